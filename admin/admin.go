@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// Backend manages ACLs and SCRAM users, either by writing znodes directly or
+// through the Kafka Admin API. Topics aren't part of it since they are
+// already changed through the Kafka Admin API in both modes.
+type Backend interface {
+	Acls(p zookeeper.Permissions) (zookeeper.ACLRules, error)
+	ApplyAcls(add, remove []zookeeper.AclRequest) error
+	Users(username string, p zookeeper.Permissions) (zookeeper.UserList, error)
+	CreateUser(name, password string) error
+	DeleteUser(name string) error
+}
+
+var backend Backend = zookeeperBackend{}
+
+// Setup selects the backend, valid names are zookeeper and kafka
+func Setup(name string) error {
+	switch name {
+	case "zookeeper":
+		backend = zookeeperBackend{}
+	case "kafka":
+		backend = kafkaBackend{}
+	default:
+		return fmt.Errorf("Unknown admin backend %s, must be zookeeper or kafka", name)
+	}
+	return nil
+}
+
+func Acls(p zookeeper.Permissions) (zookeeper.ACLRules, error) {
+	return backend.Acls(p)
+}
+
+func Acl(p zookeeper.Permissions, resourceType, name string) (zookeeper.ACLRule, error) {
+	t, err := zookeeper.AclResourceFromString(resourceType)
+	if err != nil {
+		return zookeeper.ACLRule{}, fmt.Errorf("Resource type must be one of; Topic, Group, Cluster, TransactionalId or DelegationToken, got %s", resourceType)
+	}
+	rules, err := backend.Acls(p)
+	if err != nil {
+		return zookeeper.ACLRule{}, err
+	}
+	rule, _ := rules.OfType(t).Find(name)
+	return rule, nil
+}
+
+func ApplyAcls(add, remove []zookeeper.AclRequest) error {
+	return backend.ApplyAcls(add, remove)
+}
+
+func CreateAcl(req zookeeper.AclRequest) error {
+	return backend.ApplyAcls([]zookeeper.AclRequest{req}, nil)
+}
+
+func DeleteAcl(req zookeeper.AclRequest) error {
+	return backend.ApplyAcls(nil, []zookeeper.AclRequest{req})
+}
+
+func PrincipalAcls(p zookeeper.Permissions, principal string) (zookeeper.ACLRules, error) {
+	rules, err := backend.Acls(p)
+	if err != nil {
+		return rules, err
+	}
+	return rules.Principal(principal), nil
+}
+
+// Authorize evaluates the request against every ACL in the cluster
+func Authorize(req zookeeper.AuthorizeRequest) (zookeeper.AuthorizeResult, error) {
+	rules, err := backend.Acls(zookeeper.AdminPermissions)
+	if err != nil {
+		return zookeeper.AuthorizeResult{}, err
+	}
+	return rules.Authorize(req), nil
+}
+
+func PermissionsFor(username string) (zookeeper.Permissions, error) {
+	// Using AdminPermissions here since we need permissions to see all rules
+	rules, err := backend.Acls(zookeeper.AdminPermissions)
+	if err != nil {
+		return zookeeper.Permissions{}, err
+	}
+	return zookeeper.PermissionsFromRules(username, rules), nil
+}
+
+func Users(username string, p zookeeper.Permissions) (zookeeper.UserList, error) {
+	return backend.Users(username, p)
+}
+
+func CreateUser(name, password string) error {
+	return backend.CreateUser(name, password)
+}
+
+func DeleteUser(name string) error {
+	return backend.DeleteUser(name)
+}
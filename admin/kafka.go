@@ -0,0 +1,361 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	requestTimeout  = 30 * time.Second
+	scramIterations = 4096
+	// librdkafka's RD_KAFKA_RESOURCE_TRANSACTIONAL_ID follows the broker
+	// resource, the Go client doesn't export a constant for it
+	resourceTransactionalId = kafka.ResourceBroker + 1
+)
+
+var (
+	userAlreadyExists = errors.New("User already exists")
+	userDoesNotExist  = errors.New("User does not exist")
+
+	aclOperations = map[string]kafka.ACLOperation{
+		"All":             kafka.ACLOperationAll,
+		"Read":            kafka.ACLOperationRead,
+		"Write":           kafka.ACLOperationWrite,
+		"Create":          kafka.ACLOperationCreate,
+		"Delete":          kafka.ACLOperationDelete,
+		"Alter":           kafka.ACLOperationAlter,
+		"Describe":        kafka.ACLOperationDescribe,
+		"ClusterAction":   kafka.ACLOperationClusterAction,
+		"DescribeConfigs": kafka.ACLOperationDescribeConfigs,
+		"AlterConfigs":    kafka.ACLOperationAlterConfigs,
+		"IdempotentWrite": kafka.ACLOperationIdempotentWrite,
+	}
+)
+
+// Manages ACLs and users through the Kafka Admin API, lets the brokers
+// validate the changes and works without ZooKeeper
+type kafkaBackend struct{}
+
+func adminClient() (*kafka.AdminClient, error) {
+	adminConfig := &kafka.ConfigMap{"bootstrap.servers": strings.Join(config.BrokerUrls.List(), ",")}
+	return kafka.NewAdminClient(adminConfig)
+}
+
+func (kafkaBackend) Acls(p zookeeper.Permissions) (zookeeper.ACLRules, error) {
+	a, err := adminClient()
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := a.DescribeACLs(ctx, kafka.ACLBindingFilter{
+		Type:                kafka.ResourceAny,
+		ResourcePatternType: kafka.ResourcePatternTypeAny,
+		Operation:           kafka.ACLOperationAny,
+		PermissionType:      kafka.ACLPermissionTypeAny,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Code() != kafka.ErrNoError {
+		return nil, res.Error
+	}
+	return aclRulesFromBindings(res.ACLBindings, p), nil
+}
+
+// Groups the bindings per resource and pattern, the same way as they are
+// stored in ZooKeeper
+func aclRulesFromBindings(bindings kafka.ACLBindings, p zookeeper.Permissions) zookeeper.ACLRules {
+	var (
+		keys  = make([]string, 0)
+		rules = make(map[string]*zookeeper.ACLRule)
+	)
+	for _, b := range bindings {
+		t, ok := fromKafkaResourceType(b.Type)
+		if !ok {
+			continue
+		}
+		resource := zookeeper.ACLResource{
+			Name:         b.Name,
+			ResourceType: strings.ToUpper(t.String()),
+			PatternType:  strings.ToUpper(b.ResourcePatternType.String()),
+		}
+		if !p.ReadAcl(resource) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", resource.ResourceType, resource.PatternType, resource.Name)
+		rule, ok := rules[key]
+		if !ok {
+			rule = &zookeeper.ACLRule{Resource: resource, Users: []zookeeper.UserACL{}}
+			rules[key] = rule
+			keys = append(keys, key)
+		}
+		rule.Users = append(rule.Users, zookeeper.UserACL{
+			Principal:      b.Principal,
+			PermissionType: b.PermissionType.String(),
+			Operation:      b.Operation.String(),
+			Host:           b.Host,
+		})
+	}
+	sort.Strings(keys)
+	res := make(zookeeper.ACLRules, len(keys))
+	for i, k := range keys {
+		res[i] = *rules[k]
+	}
+	return res
+}
+
+// ApplyAcls removes and then adds the ACLs. The Admin API has no
+// transactions, so when a step fails the changes already made are undone
+// to match the ZooKeeper backend, the error says if that failed too.
+func (kafkaBackend) ApplyAcls(add, remove []zookeeper.AclRequest) error {
+	var err error
+	bindings := make(kafka.ACLBindings, len(add))
+	for i, req := range add {
+		if bindings[i], err = aclBinding(req); err != nil {
+			return err
+		}
+	}
+	filters := make(kafka.ACLBindingFilters, len(remove))
+	for i, req := range remove {
+		if filters[i], err = aclBinding(req); err != nil {
+			return err
+		}
+		// Removal matches on principal, operation and permission type only
+		filters[i].Host = ""
+	}
+	a, err := adminClient()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	deleted, err := deleteAcls(ctx, a, filters)
+	if err != nil {
+		return undoAcls(a, err, nil, deleted)
+	}
+	created, err := createAcls(ctx, a, bindings)
+	if err != nil {
+		return undoAcls(a, err, created, deleted)
+	}
+	return nil
+}
+
+// deleteAcls returns the bindings that were deleted, also on error
+func deleteAcls(ctx context.Context, a *kafka.AdminClient, filters kafka.ACLBindingFilters) (kafka.ACLBindings, error) {
+	deleted := make(kafka.ACLBindings, 0)
+	if len(filters) == 0 {
+		return deleted, nil
+	}
+	results, err := a.DeleteACLs(ctx, filters, kafka.SetAdminRequestTimeout(requestTimeout))
+	if err != nil {
+		return deleted, err
+	}
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			err = r.Error
+			continue
+		}
+		deleted = append(deleted, r.ACLBindings...)
+	}
+	return deleted, err
+}
+
+// createAcls returns the bindings that were created, also on error
+func createAcls(ctx context.Context, a *kafka.AdminClient, bindings kafka.ACLBindings) (kafka.ACLBindings, error) {
+	created := make(kafka.ACLBindings, 0)
+	if len(bindings) == 0 {
+		return created, nil
+	}
+	results, err := a.CreateACLs(ctx, bindings, kafka.SetAdminRequestTimeout(requestTimeout))
+	if err != nil {
+		return created, err
+	}
+	for i, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			err = r.Error
+			continue
+		}
+		created = append(created, bindings[i])
+	}
+	return created, err
+}
+
+// undoAcls removes the created and restores the deleted bindings after err
+func undoAcls(a *kafka.AdminClient, err error, created, deleted kafka.ACLBindings) error {
+	if len(created) == 0 && len(deleted) == 0 {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, undoErr := deleteAcls(ctx, a, kafka.ACLBindingFilters(created))
+	if _, e := createAcls(ctx, a, deleted); undoErr == nil {
+		undoErr = e
+	}
+	if undoErr != nil {
+		return fmt.Errorf("%s, undoing the changes failed so the ACLs are partially applied: %s", err, undoErr)
+	}
+	return fmt.Errorf("%s, no ACLs were changed", err)
+}
+
+func aclBinding(req zookeeper.AclRequest) (kafka.ACLBinding, error) {
+	var b kafka.ACLBinding
+	t, err := toKafkaResourceType(req.ResourceType)
+	if err != nil {
+		return b, err
+	}
+	op, err := zookeeper.AclOperationFromString(req.Permission)
+	if err != nil {
+		return b, err
+	}
+	kop, ok := aclOperations[op]
+	if !ok {
+		return b, fmt.Errorf("Operation %s is not supported by the Kafka Admin API", op)
+	}
+	b = kafka.ACLBinding{
+		Type:                t,
+		Name:                req.Name,
+		ResourcePatternType: kafka.ResourcePatternTypeLiteral,
+		Principal:           req.Principal,
+		Host:                req.Data()["host"],
+		Operation:           kop,
+		PermissionType:      kafka.ACLPermissionTypeAllow,
+	}
+	if req.PatternType == zookeeper.PrefixedPattern {
+		b.ResourcePatternType = kafka.ResourcePatternTypePrefixed
+	}
+	if strings.EqualFold(req.PermissionType, "deny") {
+		b.PermissionType = kafka.ACLPermissionTypeDeny
+	}
+	return b, nil
+}
+
+func toKafkaResourceType(t zookeeper.AclResourceType) (kafka.ResourceType, error) {
+	switch t {
+	case zookeeper.TopicResource:
+		return kafka.ResourceTopic, nil
+	case zookeeper.GroupResource:
+		return kafka.ResourceGroup, nil
+	case zookeeper.ClusterResource:
+		return kafka.ResourceBroker, nil
+	case zookeeper.TransactionalIdResource:
+		return resourceTransactionalId, nil
+	}
+	return kafka.ResourceUnknown, fmt.Errorf("Resource type %s is not supported by the Kafka Admin API", t)
+}
+
+func fromKafkaResourceType(t kafka.ResourceType) (zookeeper.AclResourceType, bool) {
+	switch t {
+	case kafka.ResourceTopic:
+		return zookeeper.TopicResource, true
+	case kafka.ResourceGroup:
+		return zookeeper.GroupResource, true
+	case kafka.ResourceBroker:
+		return zookeeper.ClusterResource, true
+	case resourceTransactionalId:
+		return zookeeper.TransactionalIdResource, true
+	}
+	return -1, false
+}
+
+func describeUsers(a *kafka.AdminClient, users []string) (map[string]kafka.UserScramCredentialsDescription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := a.DescribeUserScramCredentials(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+	return res.Descriptions, nil
+}
+
+func (kafkaBackend) Users(username string, p zookeeper.Permissions) (zookeeper.UserList, error) {
+	users := make(zookeeper.UserList, 0)
+	a, err := adminClient()
+	if err != nil {
+		return users, err
+	}
+	defer a.Close()
+	descriptions, err := describeUsers(a, nil)
+	if err != nil {
+		return users, err
+	}
+	for name, d := range descriptions {
+		if d.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		if p.ReadCluster("kafka-cluster") || name == username {
+			users = append(users, name)
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+func (kafkaBackend) CreateUser(name, password string) error {
+	a, err := adminClient()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	descriptions, err := describeUsers(a, []string{name})
+	if err != nil {
+		return err
+	}
+	if d, ok := descriptions[name]; ok && d.Error.Code() == kafka.ErrNoError && len(d.ScramCredentialInfos) > 0 {
+		return userAlreadyExists
+	}
+	return alterUser(a, []kafka.UserScramCredentialUpsertion{{
+		User: name,
+		ScramCredentialInfo: kafka.ScramCredentialInfo{
+			Mechanism:  kafka.ScramMechanismSHA256,
+			Iterations: scramIterations,
+		},
+		Password: []byte(password),
+	}}, nil)
+}
+
+func (kafkaBackend) DeleteUser(name string) error {
+	a, err := adminClient()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	descriptions, err := describeUsers(a, []string{name})
+	if err != nil {
+		return err
+	}
+	d, ok := descriptions[name]
+	if !ok || d.Error.Code() != kafka.ErrNoError {
+		return userDoesNotExist
+	}
+	deletions := make([]kafka.UserScramCredentialDeletion, len(d.ScramCredentialInfos))
+	for i, info := range d.ScramCredentialInfos {
+		deletions[i] = kafka.UserScramCredentialDeletion{User: name, Mechanism: info.Mechanism}
+	}
+	return alterUser(a, nil, deletions)
+}
+
+func alterUser(a *kafka.AdminClient, upsertions []kafka.UserScramCredentialUpsertion, deletions []kafka.UserScramCredentialDeletion) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := a.AlterUserScramCredentials(ctx, upsertions, deletions)
+	if err != nil {
+		return err
+	}
+	for _, e := range res.Errors {
+		if e.Code() != kafka.ErrNoError {
+			return e
+		}
+	}
+	return nil
+}
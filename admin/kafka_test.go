@@ -0,0 +1,9 @@
+package admin
+
+import "testing"
+
+func TestResourceTransactionalId(t *testing.T) {
+	if name := resourceTransactionalId.String(); name != "TRANSACTIONAL_ID" {
+		t.Errorf("Expected TRANSACTIONAL_ID, got %s", name)
+	}
+}
@@ -0,0 +1,26 @@
+package admin
+
+import "github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+
+// Writes ACL and user znodes and change notifications directly
+type zookeeperBackend struct{}
+
+func (zookeeperBackend) Acls(p zookeeper.Permissions) (zookeeper.ACLRules, error) {
+	return zookeeper.Acls(p)
+}
+
+func (zookeeperBackend) ApplyAcls(add, remove []zookeeper.AclRequest) error {
+	return zookeeper.ApplyAcls(add, remove)
+}
+
+func (zookeeperBackend) Users(username string, p zookeeper.Permissions) (zookeeper.UserList, error) {
+	return zookeeper.Users(username, p)
+}
+
+func (zookeeperBackend) CreateUser(name, password string) error {
+	return zookeeper.CreateUser(name, password)
+}
+
+func (zookeeperBackend) DeleteUser(name string) error {
+	return zookeeper.DeleteUser(name)
+}
@@ -4,24 +4,73 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/admin"
 	"github.com/cloudkarafka/cloudkarafka-manager/config"
 	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
+	"github.com/cloudkarafka/cloudkarafka-manager/notifications"
 	"github.com/cloudkarafka/cloudkarafka-manager/server"
+	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
 	"github.com/cloudkarafka/cloudkarafka-manager/store"
-	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
 )
 
 func main() {
 	config.Parse()
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt)
-	if err := zookeeper.Connect(config.ZookeeperURL); err != nil {
-		log.Error("zk_connect", log.ErrorEntry{err})
+	if config.KRaft() && (config.AuthType == "scram" || config.AdminBackend == "zookeeper") {
+		log.Error("kraft", log.StringEntry("SCRAM login and the zookeeper admin backend require ZooKeeper, use --authentication=admin and --admin-backend=kafka"))
+		os.Exit(1)
+		return
+	}
+	if err := metadata.Start(config.ZookeeperURL, config.BootstrapServers); err != nil {
+		log.Error("metadata_connect", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := store.LoadTopicTags(); err != nil {
+		log.Error("topic_tags", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := admin.Setup(config.AdminBackend); err != nil {
+		log.Error("admin_backend", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := store.LoadCatalogue(config.MetricsCatalogue); err != nil {
+		log.Error("metrics_catalogue", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := store.CheckMetricSources(); err != nil {
+		log.Error("metric_source", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := notifications.LoadChannels(config.AlertChannels); err != nil {
+		log.Error("alert_channels", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := validators.LoadTopicPolicy(config.TopicPolicies); err != nil {
+		log.Error("topic_policies", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := notifications.StartAlerts(); err != nil {
+		log.Error("alerts", log.ErrorEntry{err})
+		os.Exit(1)
+		return
+	}
+	if err := store.StartTimeline(); err != nil {
+		log.Error("timeline", log.ErrorEntry{err})
 		os.Exit(1)
 		return
 	}
 	go store.Start()
 	go server.Start()
 	<-signals
-	zookeeper.Stop()
+	store.SaveTimeline()
+	metadata.Stop()
 }
@@ -3,9 +3,11 @@ package config
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
 	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
 )
 
@@ -15,13 +17,13 @@ func init() {
 
 func handleBrokerChanges() {
 	brokerChanges := make(chan []zookeeper.HostPort)
-	zookeeper.WatchBrokers(brokerChanges)
+	metadata.WatchBrokers(brokerChanges)
 	defer close(brokerChanges)
 	for hps := range brokerChanges {
 		hash := make(map[int]zookeeper.HostPort)
 		for _, hp := range hps {
 			le := make(log.MapEntry)
-			le[string(hp.Id)] = hp
+			le[strconv.Itoa(hp.Id)] = hp
 			if len(le) > 0 {
 				log.Info("broker_change", le)
 			}
@@ -43,14 +45,39 @@ var (
 	JMXRequestTimeout time.Duration
 	KafkaDir          string
 	ZookeeperURL      []string
+	BootstrapServers  []string
+	MetricSource      string
+	// Metric source per broker id, brokers not in the map use MetricSource
+	BrokerMetricSources map[int]string
+	JolokiaPort         int
+	MetricWorkers       int
+	MetricsCatalogue    string
+	AdminBackend        string
+	// Where alerts, silences and other state is kept, empty keeps it in memory
+	DataDir           string
+	AlertChannels     string
+	TopicPolicies     string
 	WebRequestTimeout time.Duration = 5 * time.Second
 	DevMode           bool          = false
 )
 
+// KRaft is true when brokers and topics are discovered through the Kafka
+// metadata API, ZooKeeper is not used at all then
+func KRaft() bool {
+	return len(BootstrapServers) > 0
+}
+
+func BrokerMetricSource(id int) string {
+	if s, ok := BrokerMetricSources[id]; ok {
+		return s
+	}
+	return MetricSource
+}
+
 func PrintConfig() {
 	fmt.Printf("Build info\n Version:\t%s\n Git commit:\t%s\n", Version, GitCommit)
-	fmt.Printf("Runtime\n HTTP Port:\t%s\n Auth type:\t%s\n Retention:\t%d hours\n",
-		Port, AuthType, Retention)
+	fmt.Printf("Runtime\n HTTP Port:\t%s\n Auth type:\t%s\n Admin backend:\t%s\n Retention:\t%d hours\n Data dir:\t%s\n",
+		Port, AuthType, AdminBackend, Retention, DataDir)
 
 }
 
@@ -79,6 +106,13 @@ func (b BrokerURLs) HttpUrl(k int) string {
 	return fmt.Sprintf("http://%s:1%d", b[k].Host, b[k].Port)
 }
 
+func (b BrokerURLs) JolokiaUrl(k int) string {
+	if b[k].Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("http://%s:%d/jolokia", b[k].Host, JolokiaPort)
+}
+
 // Metrics reporter exposes http server on port 10000+PLAINTEXT-PORT (19092)
 func (b BrokerURLs) MgmtUrl(k int) string {
 	if b[k].Host == "" {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,7 +14,17 @@ var (
 	requestTimeout = flag.Int("request-timeout", 5000, "Timeout in ms for requests to brokers to fetch metrics")
 	zk             = flag.String("zookeeper", "localhost:2181", "The connection string for the zookeeper connection in the form host:port. Multiple hosts can be given to allow fail-over.")
 	kafkaDir       = flag.String("kafkadir", "/opt/kafka", "The directory where kafka lives")
+	bootstrap      = flag.String("bootstrap-servers", "", "Kafka brokers to bootstrap from in the form host:port, comma separated. When set brokers and topics are discovered through the Kafka metadata API instead of ZooKeeper, for clusters running in KRaft mode.")
 	devMode        = flag.Bool("dev", false, "Devmode add more logging and reloadable assets")
+	metricSource   = flag.String("metric-source", "reporter", "Where broker metrics are read from, valid values are reporter (Kafka HTTP Reporter plugin) or jolokia")
+	brokerSources  = flag.String("broker-metric-source", "", "Per broker override of metric-source in the form id=source, comma separated, e.g. 1=jolokia,2=reporter")
+	catalogue      = flag.String("metrics-catalogue", "", "JSON file declaring which beans to collect, see store/catalogue.go. The built in catalogue is used if not set")
+	metricWorkers  = flag.Int("metric-workers", 8, "Number of brokers metrics are fetched from concurrently")
+	jolokiaPort    = flag.Int("jolokia-port", 8778, "Port the Jolokia agent listens on")
+	dataDir        = flag.String("data-dir", "", "Directory where alert rules, alert state, silences and other manager state is stored, kept in memory only if not set")
+	alertChannels  = flag.String("alert-channels", "", "JSON file with the webhook, slack and email channels alerts are delivered to, see notifications/channels.go")
+	topicPolicies  = flag.String("topic-policies", "", "JSON file with naming policies and guardrails enforced when topics are created or updated, see server/validators/policy.go")
+	adminBackend   = flag.String("admin-backend", "", "How ACLs and users are managed, valid values are zookeeper or kafka (uses the Kafka Admin API). Defaults to kafka when bootstrap-servers is set, otherwise zookeeper")
 )
 
 func Parse() {
@@ -25,5 +36,32 @@ func Parse() {
 	KafkaDir = *kafkaDir
 	ZookeeperURL = strings.Split(*zk, ",")
 	DevMode = *devMode
+	if *bootstrap != "" {
+		BootstrapServers = strings.Split(*bootstrap, ",")
+	}
+	AdminBackend = *adminBackend
+	if AdminBackend == "" {
+		AdminBackend = "zookeeper"
+		if KRaft() {
+			AdminBackend = "kafka"
+		}
+	}
+	MetricSource = *metricSource
+	BrokerMetricSources = make(map[int]string)
+	for _, s := range strings.Split(*brokerSources, ",") {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimSpace(parts[0])); err == nil {
+			BrokerMetricSources[id] = strings.TrimSpace(parts[1])
+		}
+	}
+	JolokiaPort = *jolokiaPort
+	MetricWorkers = *metricWorkers
+	MetricsCatalogue = *catalogue
+	DataDir = *dataDir
+	AlertChannels = *alertChannels
+	TopicPolicies = *topicPolicies
 	PrintConfig()
 }
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of events, the part before the dot is the resource the event is
+// about and decides who may see it
+const (
+	BrokerJoined       = "broker.joined"
+	BrokerLeft         = "broker.left"
+	BrokerRestarted    = "broker.restarted"
+	ControllerChanged  = "controller.changed"
+	TopicCreated       = "topic.created"
+	TopicDeleted       = "topic.deleted"
+	TopicConfigChanged = "topic.config_changed"
+	ISRChanged         = "partition.isr_changed"
+	AlertChanged       = "alert.changed"
+	MetricsSampled     = "metrics.sampled"
+)
+
+// Event is something that changed in the cluster or in the manager. Name
+// is the broker id, topic or alert key the event is about.
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Name string      `json:"name"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+func (e Event) Resource() string {
+	return strings.SplitN(e.Type, ".", 2)[0]
+}
+
+var (
+	lock        sync.RWMutex
+	subscribers = make(map[chan Event]bool)
+)
+
+// Subscribe returns a channel receiving all events published from now on
+// until cancel is called. Events are dropped for subscribers that don't
+// keep up, so publishers never block.
+func Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	lock.Lock()
+	subscribers[ch] = true
+	lock.Unlock()
+	cancel := func() {
+		lock.Lock()
+		defer lock.Unlock()
+		if subscribers[ch] {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// Publish sends the event to all subscribers and returns it
+func Publish(typ, name string, data interface{}) Event {
+	e := Event{Type: typ, Time: time.Now(), Name: name, Data: data}
+	lock.RLock()
+	defer lock.RUnlock()
+	for ch := range subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	return e
+}
@@ -1,13 +1,13 @@
 module github.com/cloudkarafka/cloudkarafka-manager
 
-go 1.14
+go 1.21
 
 require (
-	github.com/confluentinc/confluent-kafka-go v1.4.0
-	github.com/dustin/go-humanize v1.0.0
+	github.com/confluentinc/confluent-kafka-go/v2 v2.6.1
+	github.com/dustin/go-humanize v1.0.1
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/samuel/go-zookeeper v0.0.0-20190923202752-2cc03de413da
 	github.com/zenazn/goji v0.9.0
 	goji.io v2.0.2+incompatible
-	golang.org/x/crypto v0.0.0-20191227163750-53104e6ec876
+	golang.org/x/crypto v0.27.0
 )
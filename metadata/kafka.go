@@ -0,0 +1,249 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	pollInterval   = 10 * time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// Polls the Kafka metadata API, used for KRaft clusters where there are no
+// znodes to watch. Changes are only published when the set of brokers or
+// topics (and their partition count) differs from the previous poll.
+type kafkaSource struct {
+	bootstrap []string
+	client    *kafka.AdminClient
+	quit      chan bool
+
+	sync.RWMutex
+	brokers    map[int]kafka.BrokerMetadata
+	racks      map[int]string
+	controller int
+}
+
+func (me *kafkaSource) Start() error {
+	var err error
+	me.client, err = kafka.NewAdminClient(&kafka.ConfigMap{
+		"bootstrap.servers": strings.Join(me.bootstrap, ","),
+	})
+	if err != nil {
+		return err
+	}
+	md, err := me.refresh()
+	if err != nil {
+		me.client.Close()
+		return err
+	}
+	me.quit = make(chan bool)
+	go me.poll(md)
+	return nil
+}
+
+func (me *kafkaSource) Stop() {
+	if me.quit != nil {
+		close(me.quit)
+	}
+	if me.client != nil {
+		me.client.Close()
+	}
+}
+
+func (me *kafkaSource) poll(md *kafka.Metadata) {
+	var (
+		ticker       = time.NewTicker(pollInterval)
+		brokerHash   string
+		topicHash    string
+		publishIfNew = func(md *kafka.Metadata) {
+			hps, h := hostPorts(md)
+			if h != brokerHash {
+				brokerHash = h
+				publishBrokers(hps)
+			}
+			topics, h := topicList(md)
+			if h != topicHash {
+				topicHash = h
+				publishTopics(topics)
+			}
+		}
+	)
+	defer ticker.Stop()
+	publishIfNew(md)
+	for {
+		select {
+		case <-me.quit:
+			return
+		case <-ticker.C:
+			md, err := me.refresh()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[ERROR] metadata.poll: %s\n", err)
+				continue
+			}
+			publishIfNew(md)
+		}
+	}
+}
+
+func (me *kafkaSource) refresh() (*kafka.Metadata, error) {
+	md, err := me.client.GetMetadata(nil, true, int(requestTimeout/time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	cluster, err := me.client.DescribeCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	brokers := make(map[int]kafka.BrokerMetadata)
+	for _, b := range md.Brokers {
+		brokers[int(b.ID)] = b
+	}
+	racks := make(map[int]string)
+	for _, n := range cluster.Nodes {
+		if n.Rack != nil {
+			racks[n.ID] = *n.Rack
+		}
+	}
+	me.Lock()
+	defer me.Unlock()
+	me.brokers = brokers
+	me.racks = racks
+	me.controller = -1
+	if cluster.Controller != nil {
+		me.controller = cluster.Controller.ID
+	}
+	return md, nil
+}
+
+func hostPorts(md *kafka.Metadata) ([]zookeeper.HostPort, string) {
+	var (
+		list = make([]zookeeper.HostPort, len(md.Brokers))
+		keys = make([]string, len(md.Brokers))
+	)
+	for i, b := range md.Brokers {
+		list[i] = zookeeper.HostPort{Id: int(b.ID), Host: b.Host, Port: b.Port}
+		keys[i] = fmt.Sprintf("%d:%s:%d", b.ID, b.Host, b.Port)
+	}
+	sort.Strings(keys)
+	return list, strings.Join(keys, ",")
+}
+
+func topicList(md *kafka.Metadata) ([]zookeeper.T, string) {
+	var (
+		list = make([]zookeeper.T, 0, len(md.Topics))
+		keys = make([]string, 0, len(md.Topics))
+	)
+	for name, t := range md.Topics {
+		if t.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		partitions := make(map[string][]int)
+		for _, p := range t.Partitions {
+			partitions[strconv.Itoa(int(p.ID))] = ints(p.Replicas)
+		}
+		list = append(list, zookeeper.T{Name: name, Partitions: partitions})
+		keys = append(keys, fmt.Sprintf("%s:%d", name, len(t.Partitions)))
+	}
+	sort.Strings(keys)
+	return list, strings.Join(keys, ",")
+}
+
+func (me *kafkaSource) Broker(id int) (zookeeper.B, error) {
+	me.RLock()
+	defer me.RUnlock()
+	b, ok := me.brokers[id]
+	if !ok {
+		return zookeeper.B{Id: id}, BrokerDoesNotExistErr
+	}
+	return zookeeper.B{
+		Id:        id,
+		Host:      b.Host,
+		Port:      b.Port,
+		JmxPort:   -1,
+		Endpoints: []string{},
+		Rack:      me.racks[id],
+	}, nil
+}
+
+func (me *kafkaSource) Controller() (int, error) {
+	me.RLock()
+	defer me.RUnlock()
+	return me.controller, nil
+}
+
+// Topic always asks the cluster, so topics created or changed through the
+// manager are visible before the next poll
+func (me *kafkaSource) Topic(name string) (Topic, error) {
+	md, err := me.client.GetMetadata(&name, false, int(requestTimeout/time.Millisecond))
+	if err != nil {
+		return Topic{}, err
+	}
+	tm, ok := md.Topics[name]
+	if !ok || tm.Error.Code() == kafka.ErrUnknownTopicOrPart || tm.Error.Code() == kafka.ErrUnknownTopic {
+		return Topic{}, TopicDoesNotExistErr
+	}
+	if tm.Error.Code() != kafka.ErrNoError {
+		return Topic{}, tm.Error
+	}
+	t := Topic{
+		Name:       name,
+		Partitions: make([]Partition, len(tm.Partitions)),
+	}
+	for _, p := range tm.Partitions {
+		if int(p.ID) >= len(t.Partitions) {
+			continue
+		}
+		t.Partitions[p.ID] = Partition{
+			Number:   int(p.ID),
+			Leader:   int(p.Leader),
+			Replicas: ints(p.Replicas),
+			ISR:      ints(p.Isrs),
+		}
+	}
+	t.Config, err = me.topicConfig(name)
+	return t, err
+}
+
+// Only the values set on the topic, same as the /config/topics znode
+func (me *kafkaSource) topicConfig(name string) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	results, err := me.client.DescribeConfigs(ctx, []kafka.ConfigResource{
+		{Type: kafka.ResourceTopic, Name: name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg := make(map[string]interface{})
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			return nil, r.Error
+		}
+		for k, e := range r.Config {
+			if e.Source == kafka.ConfigSourceDynamicTopic {
+				cfg[k] = e.Value
+			}
+		}
+	}
+	return cfg, nil
+}
+
+func ints(v []int32) []int {
+	res := make([]int, len(v))
+	for i, x := range v {
+		res[i] = int(x)
+	}
+	return res
+}
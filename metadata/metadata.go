@@ -0,0 +1,122 @@
+package metadata
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+var (
+	TopicDoesNotExistErr  = errors.New("Topic does not exist")
+	BrokerDoesNotExistErr = errors.New("Broker does not exist")
+)
+
+// Source is where brokers and topics are discovered, ZooKeeper watches for
+// ZooKeeper based clusters and the Kafka metadata API for KRaft clusters.
+// Changes are pushed to the channels registered with WatchBrokers and
+// WatchTopics.
+type Source interface {
+	Start() error
+	Stop()
+	Broker(id int) (zookeeper.B, error)
+	Controller() (int, error)
+	Topic(name string) (Topic, error)
+}
+
+type Partition struct {
+	Number          int   `json:"-"`
+	Leader          int   `json:"leader"`
+	Replicas        []int `json:"-"`
+	ISR             []int `json:"isr"`
+	LeaderEpoch     int   `json:"leader_epoch"`
+	Version         int   `json:"version"`
+	ControllerEpoch int   `json:"controller_epoch"`
+}
+
+type Topic struct {
+	Name       string
+	Config     map[string]interface{}
+	Partitions []Partition
+}
+
+var (
+	source Source
+
+	// Watchers registering after the first change still get the latest list
+	listenersLock    sync.Mutex
+	brokersListeners = make([]chan []zookeeper.HostPort, 0, 10)
+	topicsListeners  = make([]chan []zookeeper.T, 0, 10)
+	lastBrokers      []zookeeper.HostPort
+	lastTopics       []zookeeper.T
+)
+
+// Start connects to ZooKeeper, or to the Kafka brokers when bootstrap
+// servers are given
+func Start(zookeeperUrls, bootstrapServers []string) error {
+	if len(bootstrapServers) > 0 {
+		source = &kafkaSource{bootstrap: bootstrapServers}
+	} else {
+		source = &zookeeperSource{urls: zookeeperUrls}
+	}
+	return source.Start()
+}
+
+func Stop() {
+	if source != nil {
+		source.Stop()
+	}
+}
+
+func WatchBrokers(ch chan []zookeeper.HostPort) {
+	listenersLock.Lock()
+	defer listenersLock.Unlock()
+	brokersListeners = append(brokersListeners, ch)
+	if lastBrokers != nil {
+		go func(list []zookeeper.HostPort) { ch <- list }(lastBrokers)
+	}
+}
+
+func WatchTopics(ch chan []zookeeper.T) {
+	listenersLock.Lock()
+	defer listenersLock.Unlock()
+	topicsListeners = append(topicsListeners, ch)
+	if lastTopics != nil {
+		go func(list []zookeeper.T) { ch <- list }(lastTopics)
+	}
+}
+
+func publishBrokers(list []zookeeper.HostPort) {
+	listenersLock.Lock()
+	lastBrokers = list
+	listeners := brokersListeners
+	listenersLock.Unlock()
+	for _, ch := range listeners {
+		ch <- list
+	}
+}
+
+func publishTopics(list []zookeeper.T) {
+	listenersLock.Lock()
+	lastTopics = list
+	listeners := topicsListeners
+	listenersLock.Unlock()
+	for _, ch := range listeners {
+		ch <- list
+	}
+}
+
+func Broker(id int) (zookeeper.B, error) {
+	return source.Broker(id)
+}
+
+// Controller returns the id of the active controller, for KRaft clusters
+// this is the broker that answered the request since the controller quorum
+// isn't exposed to clients
+func Controller() (int, error) {
+	return source.Controller()
+}
+
+func FetchTopic(name string) (Topic, error) {
+	return source.Topic(name)
+}
@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+const (
+	partitionStateAttempts   = 10
+	partitionStateRetryDelay = 100 * time.Millisecond
+)
+
+// Forwards the znode watches on /brokers/ids and /brokers/topics
+type zookeeperSource struct {
+	urls []string
+}
+
+func (me *zookeeperSource) Start() error {
+	var (
+		brokerChanges = make(chan []zookeeper.HostPort)
+		topicChanges  = make(chan []zookeeper.T)
+	)
+	zookeeper.WatchBrokers(brokerChanges)
+	zookeeper.WatchTopics(topicChanges)
+	go func() {
+		for hps := range brokerChanges {
+			publishBrokers(hps)
+		}
+	}()
+	go func() {
+		for topics := range topicChanges {
+			publishTopics(topics)
+		}
+	}()
+	return zookeeper.Connect(me.urls)
+}
+
+func (me *zookeeperSource) Stop() {
+	zookeeper.Stop()
+}
+
+func (me *zookeeperSource) Broker(id int) (zookeeper.B, error) {
+	b, err := zookeeper.Broker(id)
+	if err == zookeeper.PathDoesNotExistsErr {
+		return b, BrokerDoesNotExistErr
+	}
+	return b, err
+}
+
+func (me *zookeeperSource) Controller() (int, error) {
+	c, err := zookeeper.Controller()
+	return c.BrokerId, err
+}
+
+func (me *zookeeperSource) Topic(name string) (Topic, error) {
+	tp, err := zookeeper.Topic(name)
+	if err != nil {
+		if err == zookeeper.PathDoesNotExistsErr {
+			return Topic{}, TopicDoesNotExistErr
+		}
+		return Topic{}, err
+	}
+	t := Topic{
+		Name:       name,
+		Config:     tp.Config,
+		Partitions: make([]Partition, len(tp.Partitions)),
+	}
+	for p, replicas := range tp.Partitions {
+		var par Partition
+		partitionPath := fmt.Sprintf("/brokers/topics/%s/partitions/%s/state", name, p)
+		// The state is written by the controller shortly after a topic is
+		// created, so it may not exist yet
+		for attempt := 1; ; attempt++ {
+			err = zookeeper.Get(partitionPath, &par)
+			if err == nil {
+				break
+			}
+			if attempt == partitionStateAttempts {
+				return Topic{}, fmt.Errorf("Topic %s partition %s: %s", name, p, err)
+			}
+			time.Sleep(partitionStateRetryDelay)
+		}
+		i, _ := strconv.Atoi(p)
+		par.Replicas = replicas
+		par.Number = i
+		t.Partitions[i] = par
+	}
+	return t, nil
+}
@@ -0,0 +1,409 @@
+package notifications
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+)
+
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+
+	// Resolved alerts are kept this long before they're dropped
+	resolvedRetention = 24 * time.Hour
+
+	rulesFile    = "alert_rules.json"
+	alertsFile   = "alerts.json"
+	silencesFile = "silences.json"
+)
+
+// Alert is the state of one rule for one subject, it's pending until the
+// rule has matched for the rule's For duration, then firing until the rule
+// stops matching
+type Alert struct {
+	Key        string     `json:"key"`
+	Rule       string     `json:"rule"`
+	Subject    string     `json:"subject"`
+	Severity   string     `json:"severity"`
+	State      string     `json:"state"`
+	Value      float64    `json:"value"`
+	ActiveAt   time.Time  `json:"active_at"`
+	FiredAt    *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Silenced   bool       `json:"silenced"`
+}
+
+// Silence mutes alerts of a rule and/or subject between StartsAt and
+// EndsAt, empty Rule or Subject matches all
+type Silence struct {
+	Id        string    `json:"id"`
+	Rule      string    `json:"rule,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Comment   string    `json:"comment"`
+	CreatedBy string    `json:"created_by"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+func (s Silence) Validate() error {
+	if s.EndsAt.IsZero() {
+		return fmt.Errorf("ends_at is required")
+	}
+	if !s.StartsAt.IsZero() && !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+func (s Silence) mutes(a Alert, now time.Time) bool {
+	return (s.Rule == "" || s.Rule == a.Rule) &&
+		(s.Subject == "" || s.Subject == a.Subject) &&
+		!now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+var (
+	alertsLock sync.RWMutex
+	rules      = DefaultRules
+	alerts     = make(map[string]*Alert)
+	silences   = make([]Silence, 0)
+)
+
+// Latest value per subject of a rule, replaced in tests
+var ruleValues = Rule.values
+
+func alertKey(rule, subject string) string {
+	return rule + "/" + subject
+}
+
+// StartAlerts loads rules, alert state and silences from the data dir and
+// evaluates the rules every SampleTime. The ticker is independent of the
+// metrics sampler so an evaluation can see values up to one sample old.
+func StartAlerts() error {
+	alertsLock.Lock()
+	var loaded []Rule
+	err := store.LoadState(rulesFile, &loaded)
+	if err == nil && loaded != nil {
+		rules = loaded
+	}
+	if err == nil {
+		err = store.LoadState(alertsFile, &alerts)
+	}
+	if err == nil {
+		err = store.LoadState(silencesFile, &silences)
+	}
+	alertsLock.Unlock()
+	if err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(store.SampleTime)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			Evaluate(now)
+			Deliver(now)
+		}
+	}()
+	return nil
+}
+
+// Evaluate moves every alert to its next state and returns the alerts that
+// changed state
+func Evaluate(now time.Time) []Alert {
+	alertsLock.Lock()
+	defer alertsLock.Unlock()
+	var (
+		changed = make([]Alert, 0)
+		seen    = make(map[string]bool)
+	)
+	for _, r := range rules {
+		for subject, v := range ruleValues(r) {
+			if !r.matches(v) {
+				continue
+			}
+			key := alertKey(r.Name, subject)
+			seen[key] = true
+			a, ok := alerts[key]
+			if !ok || a.State == StateResolved {
+				a = &Alert{
+					Key:      key,
+					Rule:     r.Name,
+					Subject:  subject,
+					Severity: r.Severity,
+					State:    StatePending,
+					ActiveAt: now,
+				}
+				alerts[key] = a
+				if r.duration() > 0 {
+					changed = append(changed, *a)
+				}
+			}
+			a.Value = v
+			if a.State == StatePending && now.Sub(a.ActiveAt) >= r.duration() {
+				fired := now
+				a.State, a.FiredAt = StateFiring, &fired
+				changed = append(changed, *a)
+			}
+		}
+	}
+	for key, a := range alerts {
+		if seen[key] {
+			continue
+		}
+		switch a.State {
+		case StatePending:
+			delete(alerts, key)
+		case StateFiring:
+			resolved := now
+			a.State, a.ResolvedAt = StateResolved, &resolved
+			changed = append(changed, *a)
+		case StateResolved:
+			if now.Sub(*a.ResolvedAt) > resolvedRetention {
+				delete(alerts, key)
+			}
+		}
+	}
+	for i := range changed {
+		changed[i].Silenced = silenced(changed[i], now)
+		events.Publish(events.AlertChanged, changed[i].Key, changed[i])
+	}
+	if len(changed) > 0 {
+		if err := store.SaveState(alertsFile, alerts); err != nil {
+			log.Error("save_alerts", log.ErrorEntry{err})
+		}
+	}
+	return changed
+}
+
+func silenced(a Alert, now time.Time) bool {
+	for _, s := range silences {
+		if s.mutes(a, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Alerts returns all alerts, firing first, optionally filtered on state
+func Alerts(state string) []Alert {
+	alertsLock.RLock()
+	defer alertsLock.RUnlock()
+	var (
+		now = time.Now()
+		res = make([]Alert, 0, len(alerts))
+	)
+	for _, a := range alerts {
+		if state != "" && a.State != state {
+			continue
+		}
+		c := *a
+		c.Silenced = silenced(c, now)
+		res = append(res, c)
+	}
+	order := map[string]int{StateFiring: 0, StatePending: 1, StateResolved: 2}
+	sort.Slice(res, func(i, j int) bool {
+		if order[res[i].State] != order[res[j].State] {
+			return order[res[i].State] < order[res[j].State]
+		}
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
+
+func Rules() []Rule {
+	alertsLock.RLock()
+	defer alertsLock.RUnlock()
+	res := make([]Rule, len(rules))
+	copy(res, rules)
+	return res
+}
+
+// SaveRule adds the rule or replaces the rule with the same name
+func SaveRule(r Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	for _, c := range r.Channels {
+		if !channelExists(c) {
+			return fmt.Errorf("channel %s doesn't exist", c)
+		}
+	}
+	alertsLock.Lock()
+	defer alertsLock.Unlock()
+	updated := make([]Rule, 0, len(rules)+1)
+	for _, e := range rules {
+		if e.Name != r.Name {
+			updated = append(updated, e)
+		}
+	}
+	updated = append(updated, r)
+	if err := store.SaveState(rulesFile, updated); err != nil {
+		return err
+	}
+	rules = updated
+	resolveAlerts(r.Name, time.Now())
+	// Deliver the resolved alerts before they can start over
+	go Deliver(time.Now())
+	return nil
+}
+
+var RuleDoesNotExistErr = fmt.Errorf("Rule does not exist")
+
+// DeleteRule removes the rule and resolves its alerts
+func DeleteRule(name string) error {
+	alertsLock.Lock()
+	defer alertsLock.Unlock()
+	updated := make([]Rule, 0, len(rules))
+	for _, e := range rules {
+		if e.Name != name {
+			updated = append(updated, e)
+		}
+	}
+	if len(updated) == len(rules) {
+		return RuleDoesNotExistErr
+	}
+	if err := store.SaveState(rulesFile, updated); err != nil {
+		return err
+	}
+	rules = updated
+	resolveAlerts(name, time.Now())
+	// Deliver the resolved alerts before they can start over
+	go Deliver(time.Now())
+	return nil
+}
+
+// Alerts of a changed or deleted rule start over, firing alerts are
+// resolved rather than dropped so the resolved message is still delivered.
+// Caller must hold alertsLock.
+func resolveAlerts(rule string, now time.Time) {
+	for key, a := range alerts {
+		if a.Rule != rule {
+			continue
+		}
+		switch a.State {
+		case StatePending:
+			delete(alerts, key)
+		case StateFiring:
+			resolved := now
+			a.State, a.ResolvedAt = StateResolved, &resolved
+			c := *a
+			c.Silenced = silenced(c, now)
+			events.Publish(events.AlertChanged, key, c)
+		}
+	}
+	if err := store.SaveState(alertsFile, alerts); err != nil {
+		log.Error("save_alerts", log.ErrorEntry{err})
+	}
+}
+
+// Silences returns the silences that haven't ended yet
+func Silences() []Silence {
+	alertsLock.RLock()
+	defer alertsLock.RUnlock()
+	now := time.Now()
+	res := make([]Silence, 0, len(silences))
+	for _, s := range silences {
+		if now.Before(s.EndsAt) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func AddSilence(s Silence) (Silence, error) {
+	if s.StartsAt.IsZero() {
+		s.StartsAt = time.Now()
+	}
+	if err := s.Validate(); err != nil {
+		return s, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return s, err
+	}
+	s.Id = hex.EncodeToString(id)
+	alertsLock.Lock()
+	defer alertsLock.Unlock()
+	// Ended silences are dropped whenever a new one is added
+	now := time.Now()
+	updated := make([]Silence, 0, len(silences)+1)
+	for _, e := range silences {
+		if now.Before(e.EndsAt) {
+			updated = append(updated, e)
+		}
+	}
+	updated = append(updated, s)
+	if err := store.SaveState(silencesFile, updated); err != nil {
+		return s, err
+	}
+	silences = updated
+	return s, nil
+}
+
+var SilenceDoesNotExistErr = fmt.Errorf("Silence does not exist")
+
+func DeleteSilence(id string) error {
+	alertsLock.Lock()
+	defer alertsLock.Unlock()
+	updated := make([]Silence, 0, len(silences))
+	for _, e := range silences {
+		if e.Id != id {
+			updated = append(updated, e)
+		}
+	}
+	if len(updated) == len(silences) {
+		return SilenceDoesNotExistErr
+	}
+	if err := store.SaveState(silencesFile, updated); err != nil {
+		return err
+	}
+	silences = updated
+	return nil
+}
+
+// CheckAlerts shows the firing alerts that aren't silenced as
+// notifications
+func CheckAlerts(out chan []Notification) {
+	var (
+		res     = make([]Notification, 0)
+		ruleSet = Rules()
+	)
+	for _, a := range Alerts(StateFiring) {
+		if a.Silenced {
+			continue
+		}
+		res = append(res, alertNotification(a, ruleSet))
+	}
+	out <- res
+}
+
+func alertNotification(a Alert, rules []Rule) Notification {
+	level, _ := ParseLevel(a.Severity)
+	n := Notification{
+		Key:       a.Key,
+		Title:     a.Rule,
+		Level:     level,
+		Message:   fmt.Sprintf("%s is %v on %s", a.Rule, a.Value, a.Subject),
+		Timestamp: a.ActiveAt,
+	}
+	if a.FiredAt != nil {
+		n.Timestamp = *a.FiredAt
+	}
+	for _, r := range rules {
+		if r.Name == a.Rule && r.Message != "" {
+			n.Message = r.Message
+		}
+	}
+	if a.Subject != ScopeCluster {
+		n.Link = NotificationLink{a.Subject, "Go to " + a.Subject}
+	}
+	return n
+}
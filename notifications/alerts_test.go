@@ -0,0 +1,252 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+const testAlertKey = "urp/broker/1"
+
+var testRule = Rule{Name: "urp", Series: "under_replicated_partitions", Scope: "broker",
+	Operator: ">", Threshold: 0, Severity: "warning"}
+
+// seedAlerts replaces the rules, alerts and silences and makes the rules see
+// the values returned by values
+func seedAlerts(t *testing.T, rs []Rule, values func() map[string]float64) {
+	alertsLock.Lock()
+	rules = rs
+	alerts = make(map[string]*Alert)
+	silences = make([]Silence, 0)
+	alertsLock.Unlock()
+	ruleValues = func(Rule) map[string]float64 { return values() }
+	t.Cleanup(func() {
+		alertsLock.Lock()
+		rules = DefaultRules
+		alerts = make(map[string]*Alert)
+		silences = make([]Silence, 0)
+		alertsLock.Unlock()
+		ruleValues = Rule.values
+	})
+}
+
+func alertState(key string) string {
+	alertsLock.RLock()
+	defer alertsLock.RUnlock()
+	if a, ok := alerts[key]; ok {
+		return a.State
+	}
+	return ""
+}
+
+type evaluation struct {
+	at      time.Duration
+	value   *float64
+	state   string
+	changed int
+}
+
+func value(v float64) *float64 {
+	return &v
+}
+
+func TestEvaluate(t *testing.T) {
+	var (
+		pendingRule = testRule
+		t0          = time.Unix(1700000000, 0)
+	)
+	pendingRule.For = "1m"
+	tests := []struct {
+		name  string
+		rule  Rule
+		steps []evaluation
+	}{
+		{"pending fires after for", pendingRule, []evaluation{
+			{0, value(3), StatePending, 1},
+			{30 * time.Second, value(3), StatePending, 0},
+			{time.Minute, value(3), StateFiring, 1},
+			{2 * time.Minute, value(3), StateFiring, 0},
+		}},
+		{"pending dropped", pendingRule, []evaluation{
+			{0, value(3), StatePending, 1},
+			{30 * time.Second, value(0), "", 0},
+		}},
+		{"pending dropped when subject is gone", pendingRule, []evaluation{
+			{0, value(3), StatePending, 1},
+			{30 * time.Second, nil, "", 0},
+		}},
+		{"fires at once without for", testRule, []evaluation{
+			{0, value(3), StateFiring, 1},
+		}},
+		{"firing resolves", testRule, []evaluation{
+			{0, value(3), StateFiring, 1},
+			{10 * time.Second, value(0), StateResolved, 1},
+			{20 * time.Second, value(0), StateResolved, 0},
+		}},
+		{"resolved retention", testRule, []evaluation{
+			{0, value(3), StateFiring, 1},
+			{10 * time.Second, value(0), StateResolved, 1},
+			{10*time.Second + resolvedRetention, value(0), StateResolved, 0},
+			{11*time.Second + resolvedRetention, value(0), "", 0},
+		}},
+		{"resolved fires again", testRule, []evaluation{
+			{0, value(3), StateFiring, 1},
+			{10 * time.Second, value(0), StateResolved, 1},
+			{20 * time.Second, value(3), StateFiring, 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current *float64
+			seedAlerts(t, []Rule{tt.rule}, func() map[string]float64 {
+				if current == nil {
+					return map[string]float64{}
+				}
+				return map[string]float64{"broker/1": *current}
+			})
+			for i, s := range tt.steps {
+				current = s.value
+				changed := Evaluate(t0.Add(s.at))
+				if state := alertState(testAlertKey); state != s.state {
+					t.Errorf("Step %d: expected state %q, got %q", i, s.state, state)
+				}
+				if len(changed) != s.changed {
+					t.Errorf("Step %d: expected %d changed alerts, got %d", i, s.changed, len(changed))
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateSilenced(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	seedAlerts(t, []Rule{testRule}, func() map[string]float64 {
+		return map[string]float64{"broker/1": 3, "broker/2": 3}
+	})
+	silences = []Silence{
+		{Id: "a", Subject: "broker/1", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+		{Id: "b", Subject: "broker/2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)},
+	}
+	for _, a := range Evaluate(now) {
+		if expected := a.Subject == "broker/1"; a.Silenced != expected {
+			t.Errorf("Expected %s silenced to be %v", a.Subject, expected)
+		}
+	}
+}
+
+func TestSilenceMutes(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		a   = Alert{Rule: "urp", Subject: "broker/1"}
+	)
+	tests := []struct {
+		silence Silence
+		mutes   bool
+	}{
+		{Silence{StartsAt: now, EndsAt: now.Add(time.Hour)}, true},
+		{Silence{Rule: "urp", StartsAt: now, EndsAt: now.Add(time.Hour)}, true},
+		{Silence{Rule: "urp", Subject: "broker/1", StartsAt: now, EndsAt: now.Add(time.Hour)}, true},
+		{Silence{Rule: "lag", StartsAt: now, EndsAt: now.Add(time.Hour)}, false},
+		{Silence{Subject: "broker/2", StartsAt: now, EndsAt: now.Add(time.Hour)}, false},
+		{Silence{StartsAt: now.Add(time.Minute), EndsAt: now.Add(time.Hour)}, false},
+		{Silence{StartsAt: now.Add(-time.Hour), EndsAt: now}, false},
+	}
+	for _, tt := range tests {
+		if m := tt.silence.mutes(a, now); m != tt.mutes {
+			t.Errorf("Expected %+v to mute: %v, got %v", tt.silence, tt.mutes, m)
+		}
+	}
+}
+
+func TestSilenceValidate(t *testing.T) {
+	now := time.Now()
+	invalid := []Silence{
+		{},
+		{StartsAt: now, EndsAt: now},
+		{StartsAt: now, EndsAt: now.Add(-time.Minute)},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", s)
+		}
+	}
+	if err := (Silence{EndsAt: now.Add(time.Hour)}).Validate(); err != nil {
+		t.Errorf("Expected silence without start to be valid, got %s", err)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	for _, r := range DefaultRules {
+		if err := r.Validate(); err != nil {
+			t.Errorf("Expected default rule %s to be valid, got %s", r.Name, err)
+		}
+	}
+	invalid := []Rule{
+		{Series: "urp", Scope: "broker", Operator: ">", Severity: "warning"},
+		{Name: "a", Scope: "broker", Operator: ">", Severity: "warning"},
+		{Name: "a", Series: "urp", Scope: "partition", Operator: ">", Severity: "warning"},
+		{Name: "a", Series: "urp", Scope: "broker", Operator: "=>", Severity: "warning"},
+		{Name: "a", Series: "urp", Scope: "broker", Operator: ">", For: "soon", Severity: "warning"},
+		{Name: "a", Series: "urp", Scope: "broker", Operator: ">", For: "-1m", Severity: "warning"},
+		{Name: "a", Series: "urp", Scope: "broker", Operator: ">", Severity: "critical"},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", r)
+		}
+	}
+}
+
+func TestSaveRuleResolvesAlerts(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	seedAlerts(t, []Rule{testRule}, func() map[string]float64 {
+		return map[string]float64{"broker/1": 3}
+	})
+	Evaluate(now)
+	alerts["urp/broker/2"] = &Alert{Key: "urp/broker/2", Rule: "urp", Subject: "broker/2",
+		State: StatePending, ActiveAt: now}
+	changed := testRule
+	changed.Threshold = 5
+	if err := SaveRule(changed); err != nil {
+		t.Fatal(err)
+	}
+	if state := alertState(testAlertKey); state != StateResolved {
+		t.Errorf("Expected firing alert to be resolved, got %q", state)
+	}
+	if state := alertState("urp/broker/2"); state != "" {
+		t.Errorf("Expected pending alert to be dropped, got %q", state)
+	}
+}
+
+func TestDeleteRuleResolvesAlerts(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	seedAlerts(t, []Rule{testRule}, func() map[string]float64 {
+		return map[string]float64{"broker/1": 3}
+	})
+	Evaluate(now)
+	if err := DeleteRule("urp"); err != nil {
+		t.Fatal(err)
+	}
+	if state := alertState(testAlertKey); state != StateResolved {
+		t.Errorf("Expected firing alert to be resolved, got %q", state)
+	}
+	if err := DeleteRule("urp"); err != RuleDoesNotExistErr {
+		t.Errorf("Expected RuleDoesNotExistErr, got %v", err)
+	}
+}
+
+func TestGroupRuleValidate(t *testing.T) {
+	group := Rule{Name: "lag", Series: "lag_growth", Scope: ScopeGroup, Target: "app", Topic: "orders",
+		Operator: ">", Threshold: 0, For: "10m", Severity: "warning"}
+	if err := group.Validate(); err != nil {
+		t.Errorf("Expected group rule to be valid, got %s", err)
+	}
+	invalid := []Rule{
+		{Name: "a", Series: "under_replicated_partitions", Scope: ScopeGroup, Operator: ">", Severity: "warning"},
+		{Name: "a", Series: "under_replicated_partitions", Scope: "broker", Topic: "orders", Operator: ">", Severity: "warning"},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", r)
+		}
+	}
+}
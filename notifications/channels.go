@@ -0,0 +1,245 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Channels alerts are delivered to are read from the file given with
+// --alert-channels, e.g.
+//
+//	[{"name": "ops", "type": "slack", "url": "https://hooks.slack.com/...",
+//	  "default": true, "repeat_interval": "4h", "send_resolved": true},
+//	 {"name": "pager", "type": "webhook", "url": "http://pager.local/hook",
+//	  "headers": {"Authorization": "Bearer ..."},
+//	  "template": "{\"summary\": {{json .Title}}, \"state\": {{json .State}}}"},
+//	 {"name": "mail", "type": "email", "smtp_host": "localhost:25",
+//	  "from": "manager@example.com", "to": ["ops@example.com"]}]
+//
+// Rules without channels are delivered to the default channels. Webhooks
+// post the delivery as JSON unless a template is given, the template gets
+// the Delivery and a json function.
+
+const (
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelEmail   = "email"
+
+	defaultRepeatInterval = 4 * time.Hour
+	deliveryTimeout       = 10 * time.Second
+)
+
+type ChannelConfig struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Default        bool              `json:"default"`
+	RepeatInterval string            `json:"repeat_interval"`
+	SendResolved   bool              `json:"send_resolved"`
+	URL            string            `json:"url,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Template       string            `json:"template,omitempty"`
+	SMTPHost       string            `json:"smtp_host,omitempty"`
+	Username       string            `json:"username,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	From           string            `json:"from,omitempty"`
+	To             []string          `json:"to,omitempty"`
+}
+
+// Delivery is what channels send, one per alert state change or repeat
+type Delivery struct {
+	Key       string    `json:"key"`
+	Rule      string    `json:"rule"`
+	Subject   string    `json:"subject"`
+	State     string    `json:"state"`
+	Severity  string    `json:"severity"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Value     float64   `json:"value"`
+	Link      string    `json:"link,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Channel interface {
+	Send(ctx context.Context, d Delivery) error
+}
+
+func (c ChannelConfig) repeatInterval() time.Duration {
+	if d, err := time.ParseDuration(c.RepeatInterval); err == nil {
+		return d
+	}
+	return defaultRepeatInterval
+}
+
+func (c ChannelConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.RepeatInterval != "" {
+		if d, err := time.ParseDuration(c.RepeatInterval); err != nil || d <= 0 {
+			return fmt.Errorf("repeat_interval must be a duration such as 4h, got %s", c.RepeatInterval)
+		}
+	}
+	switch c.Type {
+	case ChannelWebhook, ChannelSlack:
+		if c.URL == "" {
+			return fmt.Errorf("url is required for %s channels", c.Type)
+		}
+	case ChannelEmail:
+		if c.SMTPHost == "" || c.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("smtp_host, from and to are required for email channels")
+		}
+	default:
+		return fmt.Errorf("type must be webhook, slack or email, got %s", c.Type)
+	}
+	return nil
+}
+
+func (c ChannelConfig) channel() (Channel, error) {
+	switch c.Type {
+	case ChannelWebhook:
+		ch := webhookChannel{url: c.URL, headers: c.Headers}
+		if c.Template != "" {
+			t, err := template.New(c.Name).Funcs(template.FuncMap{"json": toJson}).Parse(c.Template)
+			if err != nil {
+				return nil, fmt.Errorf("template: %s", err)
+			}
+			ch.template = t
+		}
+		return ch, nil
+	case ChannelSlack:
+		return slackChannel{url: c.URL}, nil
+	case ChannelEmail:
+		return emailChannel{host: c.SMTPHost, username: c.Username, password: c.Password, from: c.From, to: c.To}, nil
+	}
+	return nil, fmt.Errorf("type must be webhook, slack or email, got %s", c.Type)
+}
+
+func toJson(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func postJson(ctx context.Context, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("URL %s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+type webhookChannel struct {
+	url      string
+	headers  map[string]string
+	template *template.Template
+}
+
+func (me webhookChannel) Send(ctx context.Context, d Delivery) error {
+	var (
+		body []byte
+		err  error
+	)
+	if me.template != nil {
+		var buf bytes.Buffer
+		err = me.template.Execute(&buf, d)
+		body = buf.Bytes()
+	} else {
+		body, err = json.Marshal(d)
+	}
+	if err != nil {
+		return err
+	}
+	return postJson(ctx, me.url, me.headers, body)
+}
+
+// Slack incoming webhooks, https://api.slack.com/messaging/webhooks
+type slackChannel struct {
+	url string
+}
+
+var slackColors = map[string]string{
+	"danger":  "danger",
+	"warning": "warning",
+	"info":    "#439FE0",
+}
+
+func (me slackChannel) Send(ctx context.Context, d Delivery) error {
+	color := slackColors[d.Severity]
+	if d.State == StateResolved {
+		color = "good"
+	}
+	attachment := map[string]interface{}{
+		"color":    color,
+		"title":    d.Title,
+		"text":     d.Message,
+		"fallback": subjectLine(d),
+		"ts":       d.Timestamp.Unix(),
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"text":        subjectLine(d),
+		"attachments": []interface{}{attachment},
+	})
+	if err != nil {
+		return err
+	}
+	return postJson(ctx, me.url, nil, body)
+}
+
+type emailChannel struct {
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+func (me emailChannel) Send(ctx context.Context, d Delivery) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", me.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(me.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subjectLine(d))
+	fmt.Fprintf(&msg, "Date: %s\r\n", d.Timestamp.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nRule: %s\r\nSubject: %s\r\nValue: %v\r\nState: %s\r\n",
+		d.Message, d.Rule, d.Subject, d.Value, d.State)
+	var auth smtp.Auth
+	if me.username != "" {
+		host, _, _ := net.SplitHostPort(me.host)
+		auth = smtp.PlainAuth("", me.username, me.password, host)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- smtp.SendMail(me.host, auth, me.from, me.to, msg.Bytes())
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func subjectLine(d Delivery) string {
+	return fmt.Sprintf("[%s] %s on %s", strings.ToUpper(d.State), d.Title, d.Subject)
+}
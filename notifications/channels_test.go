@@ -0,0 +1,217 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testDelivery = Delivery{
+	Key:       "urp/broker/1",
+	Rule:      "urp",
+	Subject:   "broker/1",
+	State:     StateFiring,
+	Severity:  "warning",
+	Title:     "urp",
+	Message:   "urp is 3 on broker/1",
+	Value:     3,
+	Timestamp: time.Unix(1700000000, 0),
+}
+
+func captureServer(t *testing.T) (*httptest.Server, chan *http.Request, chan []byte) {
+	var (
+		reqs   = make(chan *http.Request, 10)
+		bodies = make(chan []byte, 10)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		reqs <- r
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+	return srv, reqs, bodies
+}
+
+func TestWebhookChannel(t *testing.T) {
+	srv, reqs, bodies := captureServer(t)
+	ch, err := ChannelConfig{Name: "hook", Type: ChannelWebhook, URL: srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer x"}}.channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ch.Send(context.Background(), testDelivery); err != nil {
+		t.Fatal(err)
+	}
+	if h := (<-reqs).Header.Get("Authorization"); h != "Bearer x" {
+		t.Errorf("Expected Authorization header, got %q", h)
+	}
+	var d Delivery
+	if err = json.Unmarshal(<-bodies, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Key != testDelivery.Key || d.State != StateFiring {
+		t.Errorf("Unexpected delivery %+v", d)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	srv, _, bodies := captureServer(t)
+	ch, err := ChannelConfig{Name: "hook", Type: ChannelWebhook, URL: srv.URL,
+		Template: `{"summary": {{json .Message}}, "state": {{json .State}}}`}.channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ch.Send(context.Background(), testDelivery); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]string
+	if err = json.Unmarshal(<-bodies, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["summary"] != testDelivery.Message || body["state"] != StateFiring {
+		t.Errorf("Unexpected body %v", body)
+	}
+}
+
+func TestWebhookErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	ch, _ := ChannelConfig{Name: "hook", Type: ChannelWebhook, URL: srv.URL}.channel()
+	if err := ch.Send(context.Background(), testDelivery); err == nil {
+		t.Error("Expected error for status 500")
+	}
+}
+
+func TestSlackChannel(t *testing.T) {
+	srv, _, bodies := captureServer(t)
+	ch, _ := ChannelConfig{Name: "slack", Type: ChannelSlack, URL: srv.URL}.channel()
+	resolved := testDelivery
+	resolved.State = StateResolved
+	if err := ch.Send(context.Background(), resolved); err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Color string `json:"color"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(<-bodies, &body); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(body.Text, "[RESOLVED]") {
+		t.Errorf("Unexpected text %q", body.Text)
+	}
+	if len(body.Attachments) != 1 || body.Attachments[0].Color != "good" {
+		t.Errorf("Expected a good attachment, got %+v", body.Attachments)
+	}
+}
+
+// A minimal SMTP server that accepts one message and returns its data
+func smtpStandIn(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var (
+			r    = bufio.NewReader(conn)
+			data strings.Builder
+			body bool
+		)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if body {
+				if line == ".\r\n" {
+					body = false
+					messages <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				body = true
+				reply("354 Go ahead")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return l.Addr().String(), messages
+}
+
+func TestEmailChannel(t *testing.T) {
+	addr, messages := smtpStandIn(t)
+	ch, err := ChannelConfig{Name: "mail", Type: ChannelEmail, SMTPHost: addr,
+		From: "manager@example.com", To: []string{"ops@example.com"}}.channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ch.Send(context.Background(), testDelivery); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-messages:
+		if !strings.Contains(msg, "Subject: [FIRING] urp on broker/1") {
+			t.Errorf("Unexpected message %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No message received")
+	}
+}
+
+func TestChannelValidate(t *testing.T) {
+	invalid := []ChannelConfig{
+		{Type: ChannelWebhook, URL: "http://x"},
+		{Name: "a", Type: ChannelSlack},
+		{Name: "a", Type: ChannelEmail, SMTPHost: "localhost:25"},
+		{Name: "a", Type: "pager"},
+		{Name: "a", Type: ChannelWebhook, URL: "http://x", RepeatInterval: "often"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", c)
+		}
+	}
+}
+
+func TestRouted(t *testing.T) {
+	def := ChannelConfig{Name: "ops", Default: true}
+	other := ChannelConfig{Name: "pager"}
+	if !routed(Rule{}, def) || routed(Rule{}, other) {
+		t.Error("Rules without channels should go to default channels only")
+	}
+	r := Rule{Channels: []string{"pager"}}
+	if routed(r, def) || !routed(r, other) {
+		t.Error("Rules with channels should go to those channels only")
+	}
+}
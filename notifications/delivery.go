@@ -0,0 +1,274 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+)
+
+const deliveriesFile = "deliveries.json"
+
+type configuredChannel struct {
+	config  ChannelConfig
+	channel Channel
+}
+
+// What was last delivered for a notification key to a channel
+type sentState struct {
+	State    string    `json:"state"`
+	LastSent time.Time `json:"last_sent"`
+}
+
+var (
+	// Held through a whole Deliver so an alert isn't sent twice when
+	// deliveries overlap
+	deliverLock  sync.Mutex
+	deliveryLock sync.Mutex
+	channels     = make([]configuredChannel, 0)
+	sent         = make(map[string]sentState)
+)
+
+// LoadChannels reads the channel configuration, an empty path means
+// alerts aren't delivered anywhere
+func LoadChannels(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var configs []ChannelConfig
+	if err = json.Unmarshal(data, &configs); err != nil {
+		return fmt.Errorf("Alert channels %s: %s", path, err)
+	}
+	loaded := make([]configuredChannel, 0, len(configs))
+	names := make(map[string]bool)
+	for i, c := range configs {
+		if err = c.Validate(); err != nil {
+			return fmt.Errorf("Alert channels %s, channel %d: %s", path, i, err)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("Alert channels %s: duplicate channel %s", path, c.Name)
+		}
+		names[c.Name] = true
+		ch, err := c.channel()
+		if err != nil {
+			return fmt.Errorf("Alert channels %s, channel %s: %s", path, c.Name, err)
+		}
+		loaded = append(loaded, configuredChannel{c, ch})
+	}
+	deliveryLock.Lock()
+	defer deliveryLock.Unlock()
+	channels = loaded
+	return store.LoadState(deliveriesFile, &sent)
+}
+
+// Channels returns the channel configuration without credentials, webhook
+// URLs often have a token in the path so only scheme and host are kept
+func Channels() []ChannelConfig {
+	deliveryLock.Lock()
+	defer deliveryLock.Unlock()
+	res := make([]ChannelConfig, len(channels))
+	for i, c := range channels {
+		res[i] = c.config
+		res[i].URL = redactURL(c.config.URL)
+		res[i].Password = ""
+		res[i].Headers = nil
+	}
+	return res
+}
+
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func channelExists(name string) bool {
+	deliveryLock.Lock()
+	defer deliveryLock.Unlock()
+	for _, c := range channels {
+		if c.config.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+type pendingDelivery struct {
+	key      string
+	channel  configuredChannel
+	delivery Delivery
+}
+
+// Deliver sends firing alerts to the channels of their rule, once when they
+// start firing and again every repeat interval of the channel, and the
+// resolved message to the channels the alert was delivered to as firing.
+// Alerts are deduplicated on their notification key per channel. Failed
+// deliveries are retried on the next call. Sending is done without holding
+// the lock so a slow channel doesn't block the API.
+func Deliver(now time.Time) {
+	deliverLock.Lock()
+	defer deliverLock.Unlock()
+	var (
+		all     = Alerts("")
+		ruleSet = Rules()
+		byName  = make(map[string]Rule)
+		keys    = make(map[string]bool)
+		pending = make([]pendingDelivery, 0)
+		changed bool
+	)
+	for _, r := range ruleSet {
+		byName[r.Name] = r
+	}
+	deliveryLock.Lock()
+	for _, a := range all {
+		r, known := byName[a.Rule]
+		if a.State == StatePending || (!known && a.State == StateFiring) {
+			continue
+		}
+		for _, c := range channels {
+			key := c.config.Name + "/" + a.Key
+			prev, ok := sent[key]
+			switch a.State {
+			case StateFiring:
+				if !routed(r, c.config) {
+					continue
+				}
+				keys[key] = true
+				if a.Silenced || (ok && prev.State == StateFiring && now.Sub(prev.LastSent) < c.config.repeatInterval()) {
+					continue
+				}
+			case StateResolved:
+				// Wherever the alert was delivered as firing, also when
+				// the rule has been changed or deleted since
+				if !ok {
+					continue
+				}
+				keys[key] = true
+				if !c.config.SendResolved {
+					delete(sent, key)
+					changed = true
+					continue
+				}
+			}
+			pending = append(pending, pendingDelivery{key, c, delivery(a, ruleSet)})
+		}
+	}
+	// Alerts that are gone, or no longer routed to the channel, are
+	// forgotten
+	for key := range sent {
+		if !keys[key] {
+			delete(sent, key)
+			changed = true
+		}
+	}
+	deliveryLock.Unlock()
+
+	delivered := make([]pendingDelivery, 0, len(pending))
+	for _, p := range pending {
+		if err := send(p.channel, p.delivery); err != nil {
+			log.Error("deliver_alert", log.ErrorEntry{err})
+			continue
+		}
+		delivered = append(delivered, p)
+	}
+
+	deliveryLock.Lock()
+	defer deliveryLock.Unlock()
+	for _, p := range delivered {
+		if p.delivery.State == StateResolved {
+			delete(sent, p.key)
+		} else {
+			sent[p.key] = sentState{State: p.delivery.State, LastSent: now}
+		}
+		changed = true
+	}
+	if changed {
+		if err := store.SaveState(deliveriesFile, sent); err != nil {
+			log.Error("save_deliveries", log.ErrorEntry{err})
+		}
+	}
+}
+
+func routed(r Rule, c ChannelConfig) bool {
+	if len(r.Channels) == 0 {
+		return c.Default
+	}
+	for _, name := range r.Channels {
+		if name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func send(c configuredChannel, d Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	if err := c.channel.Send(ctx, d); err != nil {
+		return fmt.Errorf("Channel %s: %s", c.config.Name, err)
+	}
+	return nil
+}
+
+func delivery(a Alert, rules []Rule) Delivery {
+	n := alertNotification(a, rules)
+	d := Delivery{
+		Key:       n.Key,
+		Rule:      a.Rule,
+		Subject:   a.Subject,
+		State:     a.State,
+		Severity:  a.Severity,
+		Title:     n.Title,
+		Message:   n.Message,
+		Value:     a.Value,
+		Link:      n.Link.URL,
+		Timestamp: n.Timestamp,
+	}
+	if a.ResolvedAt != nil {
+		d.Timestamp = *a.ResolvedAt
+	}
+	return d
+}
+
+var ChannelDoesNotExistErr = fmt.Errorf("Channel does not exist")
+
+// TestChannel sends a made up alert to the channel
+func TestChannel(name string) error {
+	deliveryLock.Lock()
+	var (
+		c     configuredChannel
+		found bool
+	)
+	for _, cc := range channels {
+		if cc.config.Name == name {
+			c, found = cc, true
+			break
+		}
+	}
+	deliveryLock.Unlock()
+	if !found {
+		return ChannelDoesNotExistErr
+	}
+	return send(c, Delivery{
+		Key:       "test",
+		Rule:      "test",
+		Subject:   ScopeCluster,
+		State:     StateFiring,
+		Severity:  INFO.String(),
+		Title:     "Test alert",
+		Message:   fmt.Sprintf("Test alert for channel %s", name),
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDeliverResolvedOfDeletedRule(t *testing.T) {
+	srv, _, bodies := captureServer(t)
+	cfg := ChannelConfig{Name: "hook", Type: ChannelWebhook, URL: srv.URL, Default: true, SendResolved: true}
+	ch, err := cfg.channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveryLock.Lock()
+	channels = []configuredChannel{{cfg, ch}}
+	sent = make(map[string]sentState)
+	deliveryLock.Unlock()
+	t.Cleanup(func() {
+		deliverLock.Lock()
+		defer deliverLock.Unlock()
+		deliveryLock.Lock()
+		defer deliveryLock.Unlock()
+		channels = make([]configuredChannel, 0)
+		sent = make(map[string]sentState)
+	})
+	seedAlerts(t, []Rule{testRule}, func() map[string]float64 {
+		return map[string]float64{"broker/1": 3}
+	})
+
+	now := time.Now()
+	Evaluate(now)
+	Deliver(now)
+	var d Delivery
+	if err = json.Unmarshal(<-bodies, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Key != testAlertKey || d.State != StateFiring {
+		t.Fatalf("Unexpected delivery %+v", d)
+	}
+
+	if err = DeleteRule(testRule.Name); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case body := <-bodies:
+		if err = json.Unmarshal(body, &d); err != nil {
+			t.Fatal(err)
+		}
+		if d.Key != testAlertKey || d.State != StateResolved {
+			t.Errorf("Unexpected delivery %+v", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Resolved alert wasn't delivered")
+	}
+}
@@ -63,6 +63,7 @@ func List(ctx context.Context) []Notification {
 	)
 	defer close(ch)
 	checkers := []func(chan []Notification){
+		CheckAlerts,
 		//CheckURP, CheckPluginVersion,
 		//CheckBalancedLeaders, CheckISRDelta,
 	}
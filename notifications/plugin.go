@@ -17,7 +17,7 @@ func CheckPluginVersion(ch chan []Notification) {
 				Key:       fmt.Sprintf("plugin-version-%d", brokerId),
 				Title:     fmt.Sprintf("Metrics plugin not available on broker %d", brokerId),
 				Level:     INFO,
-				Message:   fmt.Sprintf("The metrics plugin is currently not available on broker %d, it was added as a plugin to the broker but the broker needs to be restarted in order for the plugin to activate. Please restart the broker. ", brokerId),
+				Message:   fmt.Sprintf("The metrics plugin is currently not available on broker %d, offsets, sizes and ISR are collected over the Kafka protocol instead but throughput metrics are missing. If the plugin was added to the broker it needs to be restarted in order for the plugin to activate.", brokerId),
 				Timestamp: time.Now(),
 			})
 		}
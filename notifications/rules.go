@@ -0,0 +1,219 @@
+package notifications
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+)
+
+const (
+	ScopeCluster = "cluster"
+	ScopeGroup   = "group"
+)
+
+// Rule raises an alert for every subject in scope whose latest value of
+// the series compares true against the threshold for at least For, e.g.
+//
+//	{"name": "urp", "series": "under_replicated_partitions", "scope": "broker",
+//	 "operator": ">", "threshold": 0, "for": "1m", "severity": "warning"}
+//
+// Series are the catalogue series of the store, Target limits the rule to
+// one broker id, topic or listener name. The cluster scope sums the broker
+// series over all brokers.
+//
+// The group scope watches consumer groups, Target is the group and Topic
+// limits it to one topic. Its series are lag (messages), lag_seconds
+// (estimated time to catch up), lag_growth (messages per second, e.g. > 0
+// for 10m means lag kept growing for 10 minutes) per group and topic, and
+// offline (1 or 0) and offline_seconds (since last seen) per group.
+type Rule struct {
+	Name      string  `json:"name"`
+	Series    string  `json:"series"`
+	Scope     string  `json:"scope"`
+	Target    string  `json:"target,omitempty"`
+	Topic     string  `json:"topic,omitempty"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	For       string  `json:"for,omitempty"`
+	Severity  string  `json:"severity"`
+	Message   string  `json:"message,omitempty"`
+	// Names of the channels alerts are delivered to, empty means the
+	// default channels
+	Channels []string `json:"channels,omitempty"`
+}
+
+var DefaultRules = []Rule{
+	{Name: "under_replicated_partitions", Series: "under_replicated_partitions", Scope: store.ScopeBroker,
+		Operator: ">", Threshold: 0, For: "1m", Severity: "warning"},
+	{Name: "offline_partitions", Series: "offline_partitions", Scope: ScopeCluster,
+		Operator: ">", Threshold: 0, Severity: "danger"},
+	{Name: "request_handler_idle", Series: "request_handler_idle", Scope: store.ScopeBroker,
+		Operator: "<", Threshold: 0.2, For: "5m", Severity: "warning"},
+}
+
+var operators = map[string]func(a, b float64) bool{
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+func ParseLevel(s string) (Level, error) {
+	for _, l := range []Level{DANGER, WARNING, INFO} {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return INFO, fmt.Errorf("severity must be danger, warning or info, got %s", s)
+}
+
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Series == "" {
+		return fmt.Errorf("series is required")
+	}
+	switch r.Scope {
+	case store.ScopeBroker, store.ScopeTopic, store.ScopeListener, ScopeCluster:
+		if r.Topic != "" {
+			return fmt.Errorf("topic is only supported for group scope")
+		}
+	case ScopeGroup:
+		if !groupSeries[r.Series] {
+			return fmt.Errorf("series for group scope must be lag, lag_seconds, lag_growth, offline or offline_seconds, got %s", r.Series)
+		}
+	default:
+		return fmt.Errorf("scope must be broker, topic, listener, cluster or group, got %s", r.Scope)
+	}
+	if _, ok := operators[r.Operator]; !ok {
+		return fmt.Errorf("operator must be one of >, >=, <, <=, == or !=, got %s", r.Operator)
+	}
+	if r.For != "" {
+		if d, err := time.ParseDuration(r.For); err != nil || d < 0 {
+			return fmt.Errorf("for must be a duration such as 5m, got %s", r.For)
+		}
+	}
+	if _, err := ParseLevel(r.Severity); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r Rule) duration() time.Duration {
+	d, _ := time.ParseDuration(r.For)
+	return d
+}
+
+func (r Rule) matches(v float64) bool {
+	return operators[r.Operator](v, r.Threshold)
+}
+
+// values returns the latest value of the series per subject, subjects are
+// the UI paths of what the value belongs to
+func (r Rule) values() map[string]float64 {
+	res := make(map[string]float64)
+	switch r.Scope {
+	case store.ScopeBroker, ScopeCluster:
+		var (
+			sum     float64
+			sampled bool
+		)
+		for _, b := range store.Brokers() {
+			if b.Series == nil || (r.Target != "" && r.Target != strconv.Itoa(b.Id)) {
+				continue
+			}
+			if v, ok := b.Series.Current(r.Series); ok {
+				res[fmt.Sprintf("broker/%d", b.Id)] = v
+				sum += v
+				sampled = true
+			}
+		}
+		if r.Scope == ScopeCluster {
+			res = make(map[string]float64)
+			if sampled {
+				res[ScopeCluster] = sum
+			}
+		}
+	case store.ScopeTopic:
+		for _, t := range store.Topics() {
+			if t.Series == nil || (r.Target != "" && r.Target != t.Name) {
+				continue
+			}
+			if v, ok := t.Series.Current(r.Series); ok {
+				res["topic/"+t.Name] = v
+			}
+		}
+	case ScopeGroup:
+		res = r.groupValues(time.Now())
+	case store.ScopeListener:
+		for _, b := range store.Brokers() {
+			if b.Listeners == nil {
+				continue
+			}
+			for name, s := range b.Listeners.All() {
+				if r.Target != "" && r.Target != name {
+					continue
+				}
+				if v, ok := s.Current(r.Series); ok {
+					res[fmt.Sprintf("broker/%d/listener/%s", b.Id, name)] = v
+				}
+			}
+		}
+	}
+	return res
+}
+
+var groupSeries = map[string]bool{
+	"lag":             true,
+	"lag_seconds":     true,
+	"lag_growth":      true,
+	"offline":         true,
+	"offline_seconds": true,
+}
+
+func (r Rule) groupValues(now time.Time) map[string]float64 {
+	res := make(map[string]float64)
+	for _, g := range store.Consumers() {
+		if r.Target != "" && r.Target != g.Name {
+			continue
+		}
+		switch r.Series {
+		case "offline":
+			v := float64(0)
+			if !g.Online {
+				v = 1
+			}
+			res["consumer/"+g.Name] = v
+			continue
+		case "offline_seconds":
+			v := float64(0)
+			if !g.Online && g.LastSeen > 0 {
+				v = now.Sub(time.Unix(g.LastSeen, 0)).Seconds()
+			}
+			res["consumer/"+g.Name] = v
+			continue
+		}
+		for _, tl := range store.GroupLag(g.Name) {
+			if r.Topic != "" && r.Topic != tl.Topic {
+				continue
+			}
+			subject := "consumer/" + g.Name + "/" + tl.Topic
+			switch r.Series {
+			case "lag":
+				res[subject] = float64(tl.Lag)
+			case "lag_seconds":
+				if tl.SecondsBehind != nil {
+					res[subject] = *tl.SecondsBehind
+				}
+			case "lag_growth":
+				res[subject] = tl.Growth
+			}
+		}
+	}
+	return res
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/admin"
 	"github.com/cloudkarafka/cloudkarafka-manager/log"
 	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
 	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
@@ -22,7 +23,7 @@ func Acls(w http.ResponseWriter, r *http.Request) {
 		aclRules zookeeper.ACLRules
 		err      error
 	)
-	aclRules, err = zookeeper.Acls(user.Permissions)
+	aclRules, err = admin.Acls(user.Permissions)
 	if err != nil {
 		jsonError(w, err.Error())
 		return
@@ -54,7 +55,7 @@ func Acl(w http.ResponseWriter, r *http.Request) {
 		n = pat.Param(r, "resourceName")
 		t = strings.ToLower(pat.Param(r, "type"))
 	)
-	data, err := zookeeper.Acl(p, t, n)
+	data, err := admin.Acl(p, t, n)
 	if err != nil {
 		jsonError(w, err.Error())
 		return
@@ -77,7 +78,7 @@ func CreateAcl(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error())
 		return
 	}
-	err = zookeeper.CreateAcl(req)
+	err = admin.CreateAcl(req)
 	if err != nil {
 		log.Error("create_acl", log.ErrorEntry{err})
 		jsonError(w, err.Error())
@@ -98,7 +99,7 @@ func DeleteAcl(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error())
 		return
 	}
-	err = zookeeper.DeleteAcl(req)
+	err = admin.DeleteAcl(req)
 	if err != nil {
 		log.Error("delete_acl", log.ErrorEntry{err})
 		jsonError(w, err.Error())
@@ -107,13 +108,195 @@ func DeleteAcl(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func SimulateAcl(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListAcls() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var data map[string]string
+	if err := parseRequestBody(r, &data); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	for _, k := range []string{"principal", "operation", "resource_type"} {
+		if data[k] == "" {
+			jsonError(w, fmt.Sprintf("Missing parameter %s", k))
+			return
+		}
+	}
+	resource, err := zookeeper.AclResourceFromString(data["resource_type"])
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	op, err := zookeeper.AclOperationFromString(data["operation"])
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if resource != zookeeper.ClusterResource && data["name"] == "" {
+		jsonError(w, "Missing parameter name")
+		return
+	}
+	principal := data["principal"]
+	if !strings.Contains(principal, ":") {
+		principal = "User:" + principal
+	}
+	res, err := admin.Authorize(zookeeper.AuthorizeRequest{
+		Principal:    principal,
+		Host:         data["host"],
+		Operation:    op,
+		ResourceType: resource,
+		Name:         data["name"],
+	})
+	if err != nil {
+		log.Error("simulate_acl", log.ErrorEntry{err})
+		jsonError(w, err.Error())
+		return
+	}
+	writeAsJson(w, res)
+}
+
+func BatchAcls(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	var body struct {
+		Add    []map[string]string `json:"add"`
+		Remove []map[string]string `json:"remove"`
+	}
+	if err := parseRequestBody(r, &body); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if (len(body.Add) > 0 && !user.Permissions.CreateAcl()) ||
+		(len(body.Remove) > 0 && !user.Permissions.DeleteAcl()) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	add, err := aclRequestsFromMaps(body.Add, true)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	remove, err := aclRequestsFromMaps(body.Remove, false)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if err = admin.ApplyAcls(add, remove); err != nil {
+		log.Error("batch_acls", log.ErrorEntry{err})
+		jsonError(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func PrincipalAcls(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListAcls() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	principal := pat.Param(r, "principal")
+	if !strings.Contains(principal, ":") {
+		principal = "User:" + principal
+	}
+	rules, err := admin.PrincipalAcls(user.Permissions, principal)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	writeAsJson(w, rules.Details())
+}
+
+func AclTemplates(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListAcls() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, zookeeper.AclTemplates)
+}
+
+// Expands the template in the request, previews the ACLs or applies them
+func AclTemplate(w http.ResponseWriter, r *http.Request) {
+	var (
+		user    = r.Context().Value("user").(mw.SessionUser)
+		preview = strings.HasSuffix(r.URL.Path, "preview")
+	)
+	if (preview && !user.Permissions.ListAcls()) || (!preview && !user.Permissions.CreateAcl()) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	tmpl, ok := zookeeper.AclTemplateByName(pat.Param(r, "name"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var data map[string]string
+	if err := parseRequestBody(r, &data); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	params := zookeeper.AclTemplateParams{
+		Principal:       data["principal"],
+		Host:            data["host"],
+		Topic:           data["topic"],
+		Group:           data["group"],
+		TransactionalId: data["transactional_id"],
+	}
+	if params.Principal != "" && !strings.Contains(params.Principal, ":") {
+		params.Principal = "User:" + params.Principal
+	}
+	if data["pattern_type"] != "" {
+		pattern, err := zookeeper.AclPatternTypeFromString(data["pattern_type"])
+		if err != nil {
+			jsonError(w, err.Error())
+			return
+		}
+		params.TopicPattern = pattern
+	}
+	reqs, err := tmpl.Expand(params)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if preview {
+		writeAsJson(w, reqs)
+		return
+	}
+	if err = admin.ApplyAcls(reqs, nil); err != nil {
+		log.Error("apply_acl_template", log.ErrorEntry{err})
+		jsonError(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeAsJson(w, reqs)
+}
+
+func aclRequestsFromMaps(acls []map[string]string, checkKeys bool) ([]zookeeper.AclRequest, error) {
+	res := make([]zookeeper.AclRequest, len(acls))
+	for i, acl := range acls {
+		req, err := aclRequestFromMap(acl, checkKeys)
+		if err != nil {
+			return res, fmt.Errorf("ACL %d: %s", i, err)
+		}
+		res[i] = req
+	}
+	return res, nil
+}
+
 func aclRequestFromHttpRequest(r *http.Request, checkKeys bool) (zookeeper.AclRequest, error) {
 	var acl map[string]string
 	err := parseRequestBody(r, &acl)
-	e := zookeeper.AclRequest{}
 	if err != nil {
-		return e, errors.New("Cannot parse request body")
+		return zookeeper.AclRequest{}, errors.New("Cannot parse request body")
 	}
+	return aclRequestFromMap(acl, checkKeys)
+}
+
+func aclRequestFromMap(acl map[string]string, checkKeys bool) (zookeeper.AclRequest, error) {
+	e := zookeeper.AclRequest{}
 	resource, err := zookeeper.AclResourceFromString(acl["resource_type"])
 	if err != nil {
 		return e, err
@@ -122,6 +305,9 @@ func aclRequestFromHttpRequest(r *http.Request, checkKeys bool) (zookeeper.AclRe
 	if err != nil {
 		return e, err
 	}
+	if pattern == zookeeper.PrefixedPattern && !resource.SupportsPrefixed() {
+		return e, fmt.Errorf("Resource type %s does not support prefixed patterns", resource)
+	}
 	if checkKeys {
 		for _, k := range []string{"name", "principal", "permission", "permission_type"} {
 			if acl[k] == "" {
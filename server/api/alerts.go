@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	n "github.com/cloudkarafka/cloudkarafka-manager/notifications"
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
+	"goji.io/pat"
+)
+
+func Alerts(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, n.Alerts(r.URL.Query().Get("state")))
+}
+
+func AlertRules(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, n.Rules())
+}
+
+func SaveAlertRule(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.AlterConfigsCluster() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var rule n.Rule
+	if err := parseRequestBody(r, &rule); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if err := n.SaveRule(rule); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	fmt.Printf("[INFO] action=save-alert-rule user=%s rule=%s\n", user.Username, rule.Name)
+	w.WriteHeader(http.StatusCreated)
+	writeAsJson(w, rule)
+}
+
+func DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.AlterConfigsCluster() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	name := pat.Param(r, "name")
+	if err := n.DeleteRule(name); err == n.RuleDoesNotExistErr {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	fmt.Printf("[INFO] action=delete-alert-rule user=%s rule=%s\n", user.Username, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func Silences(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, n.Silences())
+}
+
+func CreateSilence(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.AlterConfigsCluster() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var s n.Silence
+	if err := parseRequestBody(r, &s); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	s.CreatedBy = user.Username
+	s, err := n.AddSilence(s)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeAsJson(w, s)
+}
+
+func DeleteSilence(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.AlterConfigsCluster() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := n.DeleteSilence(pat.Param(r, "id")); err == n.SilenceDoesNotExistErr {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func AlertChannels(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, n.Channels())
+}
+
+func TestAlertChannel(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.AlterConfigsCluster() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := n.TestChannel(pat.Param(r, "name")); err == n.ChannelDoesNotExistErr {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
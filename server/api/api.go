@@ -28,18 +28,48 @@ func Router() *goji.Mux {
 	mux.Handle(pat.Get("/overview"), http.HandlerFunc(Overview))
 
 	mux.Handle(pat.Get("/brokers"), http.HandlerFunc(Brokers))
+	mux.Handle(pat.Get("/brokers/health"), http.HandlerFunc(BrokersHealth))
+	mux.Handle(pat.Get("/brokers/health/thresholds"), http.HandlerFunc(HealthThresholds))
 	mux.Handle(pat.Get("/brokers/:id"), http.HandlerFunc(Broker))
+	mux.Handle(pat.Get("/brokers/:id/disk"), http.HandlerFunc(BrokerDisk))
+	mux.Handle(pat.Get("/disk"), http.HandlerFunc(Disk))
+	mux.Handle(pat.Get("/capacity"), http.HandlerFunc(Capacity))
+	mux.Handle(pat.Get("/events"), http.HandlerFunc(Events))
+	mux.Handle(pat.Get("/timeline"), http.HandlerFunc(Timeline))
+	mux.Handle(pat.Get("/racks"), http.HandlerFunc(Racks))
+	mux.Handle(pat.Get("/notifications"), http.HandlerFunc(Notifications))
+	mux.Handle(pat.Get("/alerts"), http.HandlerFunc(Alerts))
+	mux.Handle(pat.Get("/alerts/rules"), http.HandlerFunc(AlertRules))
+	mux.Handle(pat.Post("/alerts/rules"), http.HandlerFunc(SaveAlertRule))
+	mux.Handle(pat.Delete("/alerts/rules/:name"), http.HandlerFunc(DeleteAlertRule))
+	mux.Handle(pat.Get("/alerts/silences"), http.HandlerFunc(Silences))
+	mux.Handle(pat.Post("/alerts/silences"), http.HandlerFunc(CreateSilence))
+	mux.Handle(pat.Delete("/alerts/silences/:id"), http.HandlerFunc(DeleteSilence))
+	mux.Handle(pat.Get("/alerts/channels"), http.HandlerFunc(AlertChannels))
+	mux.Handle(pat.Post("/alerts/channels/:name/test"), http.HandlerFunc(TestAlertChannel))
+	mux.Handle(pat.Get("/connections"), http.HandlerFunc(Connections))
 
 	mux.Handle(pat.Get("/consumers"), http.HandlerFunc(ListConsumerGroups))
 	mux.Handle(pat.Get("/consumers/:name"), http.HandlerFunc(ViewConsumerGroup))
+	mux.Handle(pat.Get("/consumers/:name/lag"), http.HandlerFunc(ConsumerGroupLag))
 
 	mux.Handle(pat.Get("/topics"), http.HandlerFunc(Topics))
+	mux.Handle(pat.Get("/topic-configs"), http.HandlerFunc(TopicConfigSchema))
+	mux.Handle(pat.Get("/topic-policies"), http.HandlerFunc(TopicPolicy))
 	mux.Handle(pat.Post("/topics"), http.HandlerFunc(CreateTopic))
 	mux.Handle(pat.Get("/topics/:name"), http.HandlerFunc(Topic))
 	mux.Handle(pat.Patch("/topics/:name"), http.HandlerFunc(UpdateTopic))
 	mux.Handle(pat.Delete("/topics/:name"), http.HandlerFunc(DeleteTopic))
 
 	mux.Handle(pat.Get("/topics/:name/partitions"), http.HandlerFunc(Partitions))
+	mux.Handle(pat.Get("/topics/:name/racks"), http.HandlerFunc(TopicRacks))
+	mux.Handle(pat.Get("/topics/:name/tags"), http.HandlerFunc(TopicTags))
+	mux.Handle(pat.Put("/topics/:name/tags"), http.HandlerFunc(SetTopicTags))
+	mux.Handle(pat.Get("/topics/:name/config"), http.HandlerFunc(TopicConfig))
+	mux.Handle(pat.Patch("/topics/:name/config"), http.HandlerFunc(AlterTopicConfig))
+	mux.Handle(pat.Get("/topics/:name/config/history"), http.HandlerFunc(TopicConfigHistory))
+	mux.Handle(pat.Get("/topics/:name/config/diff"), http.HandlerFunc(TopicConfigDiff))
+	mux.Handle(pat.Post("/topics/:name/config/rollback"), http.HandlerFunc(RollbackTopicConfig))
 
 	mux.Handle(pat.Get("/users"), http.HandlerFunc(Users))
 	mux.Handle(pat.Post("/users"), http.HandlerFunc(CreateUser))
@@ -50,10 +80,18 @@ func Router() *goji.Mux {
 	mux.Handle(pat.Get("/acls/:type/:resourceName/users"), http.HandlerFunc(Acl))
 	mux.Handle(pat.Post("/acls"), http.HandlerFunc(CreateAcl))
 	mux.Handle(pat.Delete("/acls"), http.HandlerFunc(DeleteAcl))
+	mux.Handle(pat.Post("/acls/simulate"), http.HandlerFunc(SimulateAcl))
+	mux.Handle(pat.Post("/acls/batch"), http.HandlerFunc(BatchAcls))
+	mux.Handle(pat.Get("/acls/templates"), http.HandlerFunc(AclTemplates))
+	mux.Handle(pat.Post("/acls/templates/:name/preview"), http.HandlerFunc(AclTemplate))
+	mux.Handle(pat.Post("/acls/templates/:name"), http.HandlerFunc(AclTemplate))
+	mux.Handle(pat.Get("/principals/:principal/acls"), http.HandlerFunc(PrincipalAcls))
 
 	mux.Handle(pat.Post("/metricsbatch"), http.HandlerFunc(KafkaMetrics)) // legacy route
 	mux.Handle(pat.Post("/metrics/kafka"), http.HandlerFunc(KafkaMetrics))
 	mux.Handle(pat.Get("/metrics/zookeeper"), http.HandlerFunc(ZookeeperMetrics))
+	mux.Handle(pat.Get("/metrics/samples"), http.HandlerFunc(MetricSamples))
+	mux.Handle(pat.Get("/metrics/catalogue"), http.HandlerFunc(MetricCatalogue))
 
 	mux.Handle(pat.Get("/config/kafka"), http.HandlerFunc(GetKafkaConfig))
 	mux.Handle(pat.Get("/config/kafka/:brokerId"), http.HandlerFunc(GetKafkaConfigBroker))
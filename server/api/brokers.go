@@ -1,7 +1,9 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
 	"github.com/cloudkarafka/cloudkarafka-manager/store"
@@ -9,18 +11,23 @@ import (
 )
 
 type brokerVM struct {
-	Id           int    `json:"id"`
-	KafkaVersion string `json:"kafka_version"`
-	Host         string `json:"host"`
-	Controller   bool   `json:"controller"`
-	Uptime       string `json:"uptime"`
-	BytesIn      []int  `json:"bytes_in,omitempty"`
-	BytesOut     []int  `json:"bytes_out,omitempty"`
-	ISRShrink    []int  `json:"isr_shrink,omitempty"`
-	ISRExpand    []int  `json:"isr_expand,omitempty"`
-	Leader       int    `json:"leader"`
-	Partitions   int    `json:"partitions"`
-	TopicSize    string `json:"topic_size"`
+	Id           int                         `json:"id"`
+	KafkaVersion string                      `json:"kafka_version"`
+	Host         string                      `json:"host"`
+	Rack         string                      `json:"rack"`
+	Controller   bool                        `json:"controller"`
+	Uptime       string                      `json:"uptime"`
+	BytesIn      []int                       `json:"bytes_in,omitempty"`
+	BytesOut     []int                       `json:"bytes_out,omitempty"`
+	ISRShrink    []int                       `json:"isr_shrink,omitempty"`
+	ISRExpand    []int                       `json:"isr_expand,omitempty"`
+	Leader       int                         `json:"leader"`
+	Partitions   int                         `json:"partitions"`
+	TopicSize    string                      `json:"topic_size"`
+	Series       *store.SeriesSet            `json:"series,omitempty"`
+	Listeners    *store.SeriesGroup          `json:"listeners,omitempty"`
+	Health       store.Health                `json:"health"`
+	Connections  []store.ListenerConnections `json:"connections,omitempty"`
 }
 
 func Brokers(w http.ResponseWriter, r *http.Request) {
@@ -35,8 +42,10 @@ func Brokers(w http.ResponseWriter, r *http.Request) {
 			Id:           b.Id,
 			KafkaVersion: b.KafkaVersion,
 			Host:         b.Host,
+			Rack:         b.Rack,
 			Controller:   b.Controller,
 			Uptime:       b.Uptime(),
+			Health:       store.BrokerHealth(b),
 		}
 	}
 	writeAsJson(w, brokers)
@@ -59,6 +68,7 @@ func Broker(w http.ResponseWriter, r *http.Request) {
 		Id:           b.Id,
 		KafkaVersion: b.KafkaVersion,
 		Host:         b.Host,
+		Rack:         b.Rack,
 		Controller:   b.Controller,
 		Uptime:       b.Uptime(),
 		BytesIn:      b.BytesIn.Points,
@@ -68,5 +78,105 @@ func Broker(w http.ResponseWriter, r *http.Request) {
 		Partitions:   pc,
 		Leader:       lc,
 		TopicSize:    ts,
+		Series:       b.Series,
+		Listeners:    b.Listeners,
+		Health:       store.BrokerHealth(b),
+		Connections:  store.BrokerConnections(b),
 	})
 }
+
+func BrokersHealth(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, store.ClusterHealthReport())
+}
+
+func HealthThresholds(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, store.HealthThresholds)
+}
+
+func Connections(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeAsJson(w, store.ClusterConnections())
+}
+
+func topParam(r *http.Request) (int, error) {
+	top := r.URL.Query().Get("top")
+	if top == "" {
+		return 10, nil
+	}
+	n, err := strconv.Atoi(top)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("top must be a positive number")
+	}
+	return n, nil
+}
+
+func BrokerDisk(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(pat.Param(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	top, err := topParam(r)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	report, ok := store.BrokerDiskUsage(id, -1)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeAsJson(w, topPartitions(report, top, user))
+}
+
+// topPartitions keeps the top biggest partitions of topics the user can see
+func topPartitions(report store.DiskReport, top int, user mw.SessionUser) store.DiskReport {
+	res := make([]store.PartitionUsage, 0, top)
+	for _, p := range report.TopPartitions {
+		if len(res) == top {
+			break
+		}
+		if user.Permissions.DescribeTopic(p.Topic) {
+			res = append(res, p)
+		}
+	}
+	report.TopPartitions = res
+	return report
+}
+
+func Disk(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	top, err := topParam(r)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	reports := store.ClusterDiskUsage(-1)
+	for i, report := range reports {
+		reports[i] = topPartitions(report, top, user)
+	}
+	writeAsJson(w, reports)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+)
+
+func Capacity(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	report := store.Capacity()
+	topics := make([]store.TopicCapacity, 0, len(report.Topics))
+	for _, t := range report.Topics {
+		if user.Permissions.DescribeTopic(t.Name) {
+			topics = append(topics, t)
+		}
+	}
+	report.Topics = topics
+	unbounded := make([]string, 0, len(report.Unbounded))
+	for _, name := range report.Unbounded {
+		if user.Permissions.DescribeTopic(name) {
+			unbounded = append(unbounded, name)
+		}
+	}
+	report.Unbounded = unbounded
+	writeAsJson(w, report)
+}
@@ -41,3 +41,17 @@ func ViewConsumerGroup(w http.ResponseWriter, r *http.Request) {
 	}
 	writeAsJson(w, g)
 }
+
+func ConsumerGroupLag(w http.ResponseWriter, r *http.Request) {
+	group := pat.Param(r, "name")
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.DescribeGroup(group) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Consumer(group); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeAsJson(w, store.GroupLag(group))
+}
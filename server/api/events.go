@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
+	"github.com/cloudkarafka/cloudkarafka-manager/notifications"
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// Events streams cluster events, optionally only the types starting with
+// one of the comma separated prefixes in the types query parameter, e.g.
+// ?types=topic,broker.joined
+func Events(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "Streaming unsupported!", http.StatusBadRequest)
+		return
+	}
+	var types []string
+	if t := r.URL.Query().Get("types"); t != "" {
+		types = strings.Split(t, ",")
+	}
+	ch, cancel := events.Subscribe(100)
+	defer cancel()
+	SetupSSE(15000, rw)
+	flusher.Flush()
+	fmt.Fprintf(os.Stderr, "[INFO] action=events user=%s\n", user.Username)
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(rw, ":\n\n")
+			flusher.Flush()
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !eventWanted(e, types) || !eventAllowed(e, user.Permissions) {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func eventWanted(e events.Event, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if strings.HasPrefix(e.Type, strings.TrimSpace(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Topic and partition events are visible to those who may describe the
+// topic, alerts to those who may see their subject, the rest is cluster
+// wide and requires the right to list brokers
+func eventAllowed(e events.Event, p zookeeper.Permissions) bool {
+	switch e.Resource() {
+	case "topic", "partition":
+		return p.DescribeTopic(e.Name)
+	case "alert":
+		if a, ok := e.Data.(notifications.Alert); ok {
+			return subjectAllowed(a.Subject, p)
+		}
+		return p.ListBrokers()
+	default:
+		return p.ListBrokers()
+	}
+}
+
+// subjectAllowed checks an alert subject, topic/<topic>, consumer/<group>
+// or consumer/<group>/<topic>, other subjects are about the cluster
+func subjectAllowed(subject string, p zookeeper.Permissions) bool {
+	parts := strings.SplitN(subject, "/", 3)
+	switch parts[0] {
+	case "topic":
+		return len(parts) > 1 && p.DescribeTopic(parts[1])
+	case "consumer":
+		if len(parts) < 2 || !p.DescribeGroup(parts[1]) {
+			return false
+		}
+		return len(parts) < 3 || p.DescribeTopic(parts[2])
+	}
+	return p.ListBrokers()
+}
+
+// Timeline returns the cluster timeline between from and to, RFC 3339 or
+// unix seconds, by default the last 24 hours
+func Timeline(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	q := r.URL.Query()
+	to, err := timeParam(q.Get("to"), time.Now())
+	if err != nil {
+		jsonError(w, "to: "+err.Error())
+		return
+	}
+	from, err := timeParam(q.Get("from"), to.Add(-24*time.Hour))
+	if err != nil {
+		jsonError(w, "from: "+err.Error())
+		return
+	}
+	if from.After(to) {
+		jsonError(w, "from must be before to")
+		return
+	}
+	var types []string
+	if t := q.Get("types"); t != "" {
+		types = strings.Split(t, ",")
+	}
+	entries := store.Timeline(from, to, types)
+	res := make([]store.TimelineEntry, 0, len(entries))
+	for _, e := range entries {
+		if eventAllowed(e.Event, user.Permissions) {
+			res = append(res, e)
+		}
+	}
+	writeAsJson(w, res)
+}
+
+func timeParam(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return def, nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, fmt.Errorf("must be RFC 3339 or unix seconds")
+	}
+	return t, nil
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
 )
 
 func writeAsJson(w http.ResponseWriter, bytes interface{}) {
@@ -23,6 +26,38 @@ func jsonError(w http.ResponseWriter, msg string) {
 	writeAsJson(w, map[string]string{"reason": msg})
 }
 
+// fieldErrors responds 400 with one error per invalid field
+func fieldErrors(w http.ResponseWriter, errs []validators.FieldError) {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	writeAsJson(w, map[string]interface{}{
+		"reason": strings.Join(msgs, "\n"),
+		"errors": errs,
+	})
+}
+
+// policyErrors responds 400 with all violations if any of them is an
+// error, warnings alone let the request through
+func policyErrors(w http.ResponseWriter, violations []validators.Violation) bool {
+	errs := validators.Errors(violations)
+	if len(errs) == 0 {
+		return false
+	}
+	msgs := make([]string, len(errs))
+	for i, v := range errs {
+		msgs[i] = v.Field + ": " + v.Message
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	writeAsJson(w, map[string]interface{}{
+		"reason":     strings.Join(msgs, "\n"),
+		"violations": violations,
+	})
+	return true
+}
+
 func parseRequestBody(r *http.Request, target interface{}) error {
 	switch r.Header.Get("content-type") {
 	case "application/json":
@@ -41,6 +41,14 @@ func KafkaMetrics(w http.ResponseWriter, r *http.Request) {
 	writeAsJson(w, all)
 }
 
+func MetricSamples(w http.ResponseWriter, r *http.Request) {
+	writeAsJson(w, store.SampleHistory())
+}
+
+func MetricCatalogue(w http.ResponseWriter, r *http.Request) {
+	writeAsJson(w, store.Catalogue())
+}
+
 func ZookeeperMetrics(w http.ResponseWriter, r *http.Request) {
 	res := make(map[int]map[string]interface{})
 	for id, hp := range config.BrokerUrls {
@@ -1,37 +1,10 @@
 package api
 
 import (
-	"fmt"
 	"regexp"
-)
 
-var validConfigKeys = []string{
-	"cleanup.policy",
-	"compression.type",
-	"delete.retention.ms",
-	"file.delete.delay.ms",
-	"flush.messages",
-	"flush.ms",
-	"follower.replication.throttled.replicas",
-	"index.interval.bytes",
-	"leader.replication.throttled.replicas",
-	"max.message.bytes",
-	"message.format.version",
-	"message.timestamp.difference.max.ms",
-	"message.timestamp.type",
-	"min.cleanable.dirty.ratio",
-	"min.compaction.lag.ms",
-	"min.insync.replicas",
-	"preallocate",
-	"retention.bytes",
-	"retention.ms",
-	"segment.bytes",
-	"segment.index.bytes",
-	"segment.jitter.ms",
-	"segment.ms",
-	"unclean.leader.election.enable",
-	"message.downconversion.enable",
-}
+	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
+)
 
 type TopicModel struct {
 	Name              string                 `json:"name"`
@@ -57,22 +30,10 @@ func (topic TopicModel) Validate() []string {
 	return append(res, topic.validateConfig()...)
 }
 
-func (topic TopicModel) isValidConfigKey(key string) bool {
-	for _, vck := range validConfigKeys {
-		if key == vck {
-			return true
-		}
-	}
-	return false
-}
-
 func (topic TopicModel) validateConfig() []string {
 	var errs []string
-	for k, v := range topic.Config {
-		if !topic.isValidConfigKey(k) {
-			errs = append(errs, fmt.Sprintf("%s=%s", k, v))
-		}
+	for _, e := range validators.ValidateTopicConfig(topic.Config, false) {
+		errs = append(errs, e.Error())
 	}
 	return errs
-
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudkarafka/cloudkarafka-manager/config"
 	n "github.com/cloudkarafka/cloudkarafka-manager/notifications"
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
 )
 
 type notification struct {
@@ -19,6 +20,12 @@ var (
 	}
 )
 func Notifications(w http.ResponseWriter, r *http.Request) {
+	// Firing alerts are about the whole cluster, like /alerts
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 	ctx, cancel := context.WithTimeout(r.Context(), config.JMXRequestTimeout)
 	defer cancel()
 	writeAsJson(w, n.List(ctx))
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
+	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+	"goji.io/pat"
+)
+
+// remarshal converts a decoded JSON value into target
+func remarshal(v interface{}, target interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// filterByTags keeps the topics matching the owner, classification and
+// label query parameters, label can be given many times as key or
+// key=value, e.g. ?owner=payments&label=pii&label=tier=1
+func filterByTags(topics store.TopicSlice, q url.Values) store.TopicSlice {
+	var (
+		owner          = q.Get("owner")
+		classification = q.Get("classification")
+		labels         = q["label"]
+	)
+	if owner == "" && classification == "" && len(labels) == 0 {
+		return topics
+	}
+	res := make(store.TopicSlice, 0, len(topics))
+	for _, t := range topics {
+		if t.Tags == nil {
+			continue
+		}
+		if owner != "" && t.Tags.Owner != owner {
+			continue
+		}
+		if classification != "" && t.Tags.Classification != classification {
+			continue
+		}
+		if hasLabels(t.Tags.Labels, labels) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func hasLabels(labels map[string]string, wanted []string) bool {
+	for _, l := range wanted {
+		parts := strings.SplitN(l, "=", 2)
+		v, ok := labels[parts[0]]
+		if !ok || (len(parts) == 2 && v != parts[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TopicTags(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+	)
+	if !user.Permissions.DescribeTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Topic(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	tags, _ := store.TopicTags(name)
+	writeAsJson(w, tags)
+}
+
+// SetTopicTags replaces the tags of the topic
+func SetTopicTags(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+		tags zookeeper.TopicTags
+	)
+	if !user.Permissions.ReadTopic(name) || !user.Permissions.UpdateTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Topic(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := parseRequestBody(r, &tags); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if err := tags.Validate(); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	violations := validators.Policy().CheckTags(validators.TopicRequest{
+		Name:   name,
+		Owner:  tags.Owner,
+		Labels: tags.Labels,
+	})
+	if policyErrors(w, violations) {
+		return
+	}
+	tags, err := store.SetTopicTags(name, tags, user.Username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Printf("[INFO] action=set-topic-tags user=%s topic=%s\n", user.Username, name)
+	writeAsJson(w, tags)
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
+	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
+	"github.com/cloudkarafka/cloudkarafka-manager/store"
+	"goji.io/pat"
+)
+
+// TopicConfigSchema lists the known topic configs so forms can be built
+// and values checked before they're sent
+func TopicConfigSchema(w http.ResponseWriter, r *http.Request) {
+	writeAsJson(w, validators.TopicConfigs)
+}
+
+func TopicPolicy(w http.ResponseWriter, r *http.Request) {
+	writeAsJson(w, validators.Policy())
+}
+
+// TopicConfig returns the effective config of the topic with the source
+// of every value
+func TopicConfig(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+	)
+	if !user.Permissions.DescribeTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Topic(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	entries, err := store.DescribeTopicConfig(ctx, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeAsJson(w, entries)
+}
+
+// AlterTopicConfig applies incremental operations to the topic config,
+// e.g. {"operations": [{"op": "delete", "name": "retention.ms"}]}, and
+// returns the effective config
+func AlterTopicConfig(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+		body struct {
+			Operations []store.ConfigOp `json:"operations"`
+		}
+	)
+	if !user.Permissions.ReadTopic(name) || !user.Permissions.UpdateTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	topic, ok := store.Topic(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := parseRequestBody(r, &body); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if len(body.Operations) == 0 {
+		jsonError(w, "operations is required")
+		return
+	}
+	errs := make([]validators.FieldError, 0)
+	for i, o := range body.Operations {
+		if err := o.Validate(); err != nil {
+			errs = append(errs, validators.FieldError{Field: fmt.Sprintf("operations[%d]", i), Message: err.Error()})
+			continue
+		}
+		errs = append(errs, validators.ValidateConfigOp(i, o.Op, o.Name, o.Value)...)
+	}
+	if len(errs) > 0 {
+		fieldErrors(w, errs)
+		return
+	}
+	violations := validators.Policy().CheckUpdate(opsRequest(name, body.Operations), topic.ReplicationFactor())
+	if policyErrors(w, violations) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	if err := store.AlterTopicConfig(ctx, name, body.Operations, user.Username); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	fmt.Printf("[INFO] action=alter-topic-config user=%s topic=%s operations=%d\n", user.Username, name, len(body.Operations))
+	store.UpdateTopic(name)
+	entries, err := store.DescribeTopicConfig(ctx, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeAsJson(w, entries)
+}
+
+// opsRequest is what the operations change, for the topic policy
+func opsRequest(name string, ops []store.ConfigOp) validators.TopicRequest {
+	req := validators.TopicRequest{
+		Name:   name,
+		Config: make(map[string]string),
+		Append: make(map[string]string),
+	}
+	for _, o := range ops {
+		switch o.Op {
+		case store.ConfigOpSet:
+			req.Config[o.Name] = o.Value
+		case store.ConfigOpDelete:
+			req.Delete = append(req.Delete, o.Name)
+		case store.ConfigOpAppend:
+			req.Append[o.Name] = o.Value
+		}
+	}
+	return req
+}
+
+func TopicConfigHistory(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+	)
+	if !user.Permissions.DescribeTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Topic(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeAsJson(w, store.ConfigHistory(name))
+}
+
+func versionParam(r *http.Request, key string) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a version number", key)
+	}
+	return n, nil
+}
+
+// TopicConfigDiff compares two versions of the topic config, a missing
+// or 0 version is the current config
+func TopicConfigDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+	)
+	if !user.Permissions.DescribeTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := store.Topic(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	from, err := versionParam(r, "from")
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	to, err := versionParam(r, "to")
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	a, err := store.ConfigVersionOf(name, from)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("from: %s", err))
+		return
+	}
+	b, err := store.ConfigVersionOf(name, to)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("to: %s", err))
+		return
+	}
+	writeAsJson(w, map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"changes": store.DiffConfig(a.Config, b.Config),
+	})
+}
+
+func RollbackTopicConfig(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+		body struct {
+			Version int `json:"version"`
+		}
+	)
+	if !user.Permissions.ReadTopic(name) || !user.Permissions.UpdateTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	topic, ok := store.Topic(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := parseRequestBody(r, &body); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	if body.Version <= 0 {
+		jsonError(w, "version is required")
+		return
+	}
+	// Policies may have changed since the version was made
+	v, err := store.ConfigVersionOf(name, body.Version)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	ops, err := store.RollbackOps(name, body.Version)
+	if err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	req := opsRequest(name, ops)
+	req.Config = v.Config
+	if policyErrors(w, validators.Policy().CheckUpdate(req, topic.ReplicationFactor())) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	if err := store.RollbackTopicConfig(ctx, name, body.Version, ops, user.Username); err != nil {
+		jsonError(w, err.Error())
+		return
+	}
+	fmt.Printf("[INFO] action=rollback-topic-config user=%s topic=%s version=%d\n", user.Username, name, body.Version)
+	store.UpdateTopic(name)
+	history := store.ConfigHistory(name)
+	writeAsJson(w, history[len(history)-1])
+}
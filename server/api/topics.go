@@ -9,6 +9,7 @@ import (
 	"time"
 
 	c "github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
 	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
 	"github.com/cloudkarafka/cloudkarafka-manager/server/validators"
 	"github.com/cloudkarafka/cloudkarafka-manager/store"
@@ -21,6 +22,7 @@ func Topics(w http.ResponseWriter, r *http.Request) {
 		user   = r.Context().Value("user").(mw.SessionUser)
 		topics = topics(user.Permissions.DescribeTopic)
 	)
+	topics = filterByTags(topics, r.URL.Query())
 	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
 	ps, p, err := pageInfo(r)
 	if err != nil {
@@ -101,12 +103,40 @@ func CreateTopic(w http.ResponseWriter, r *http.Request) {
 			jsonError(w, "config must be a hashmap of string=>string")
 			return
 		}
+		if errs := validators.ValidateTopicConfig(cfg, false); len(errs) > 0 {
+			fieldErrors(w, errs)
+			return
+		}
 		for k, v := range cfg {
-			if config[k], ok = v.(string); !ok {
-				continue
-			}
+			config[k] = v.(string)
+		}
+	}
+	var tags zookeeper.TopicTags
+	if data["tags"] != nil {
+		if err = remarshal(data["tags"], &tags); err != nil {
+			jsonError(w, "tags must be an object")
+			return
+		}
+		if err = tags.Validate(); err != nil {
+			jsonError(w, err.Error())
+			return
 		}
 	}
+	existing := make([]string, 0)
+	for _, t := range topics(user.Permissions.DescribeTopic) {
+		existing = append(existing, t.Name)
+	}
+	violations := validators.Policy().CheckCreate(validators.TopicRequest{
+		Name:              name,
+		Partitions:        int(partitions),
+		ReplicationFactor: int(replicationFactor),
+		Config:            config,
+		Owner:             tags.Owner,
+		Labels:            tags.Labels,
+	}, existing)
+	if policyErrors(w, violations) {
+		return
+	}
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 	err = store.CreateTopic(ctx, name, int(partitions), int(replicationFactor), config)
@@ -114,7 +144,15 @@ func CreateTopic(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error())
 		return
 	}
+	if data["tags"] != nil {
+		if _, err = store.SetTopicTags(name, tags, user.Username); err != nil {
+			log.Error("create_topic_tags", log.ErrorEntry{err})
+		}
+	}
 	w.WriteHeader(http.StatusCreated)
+	if len(violations) > 0 {
+		writeAsJson(w, map[string]interface{}{"warnings": violations})
+	}
 }
 
 func UpdateTopic(w http.ResponseWriter, r *http.Request) {
@@ -129,7 +167,7 @@ func UpdateTopic(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	if !zookeeper.Exists(fmt.Sprintf("/brokers/topics/%s", name)) {
+	if _, ok := store.Topic(name); !ok {
 		http.NotFound(w, r)
 		return
 	}
@@ -140,44 +178,86 @@ func UpdateTopic(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error())
 		return
 	}
+	topic, ok := store.Topic(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var (
+		partitions int
+		config     map[string]interface{}
+	)
 	if data["partitions"] != nil {
-		topic, ok := store.Topic(name)
-		if !ok {
-			http.NotFound(w, r)
-			return
-		}
 		var partitions_f float64
 		if partitions_f, ok = data["partitions"].(float64); !ok {
 			jsonError(w, "partitions must be an integer")
 			return
 		}
-		partitions := int(partitions_f)
+		partitions = int(partitions_f)
 		if partitions < len(topic.Partitions) {
 			msg := fmt.Sprintf("You can only add partitions to topic, topic has %d partitions", len(topic.Partitions))
 			jsonError(w, msg)
 			return
-		} else if partitions > len(topic.Partitions) {
-			if err = store.AddParitions(ctx, name, partitions); err != nil {
-				jsonError(w, err.Error())
-				return
-			}
 		}
 	}
 	if data["config"] != nil {
-		var config map[string]interface{}
 		if config, ok = data["config"].(map[string]interface{}); !ok {
 			jsonError(w, "config must be a hashmap of string=>string")
 			return
 		}
-		if len(config) > 0 {
-			if err = store.UpdateTopicConfig(ctx, name, config); err != nil {
-				jsonError(w, err.Error())
-				return
-			}
+		if errs := validators.ValidateTopicConfig(config, true); len(errs) > 0 {
+			fieldErrors(w, errs)
+			return
+		}
+	}
+	req := validators.TopicRequest{Name: name, Config: setValues(config), Delete: nullValues(config)}
+	if partitions > len(topic.Partitions) {
+		req.Partitions = partitions
+	}
+	violations := validators.Policy().CheckUpdate(req, topic.ReplicationFactor())
+	if policyErrors(w, violations) {
+		return
+	}
+	if req.Partitions > 0 {
+		if err = store.AddParitions(ctx, name, partitions); err != nil {
+			jsonError(w, err.Error())
+			return
+		}
+	}
+	if len(config) > 0 {
+		if err = store.UpdateTopicConfig(ctx, name, config, user.Username); err != nil {
+			jsonError(w, err.Error())
+			return
 		}
 	}
 	store.UpdateTopic(name)
 	w.WriteHeader(http.StatusOK)
+	if len(violations) > 0 {
+		writeAsJson(w, map[string]interface{}{"warnings": violations})
+	}
+}
+
+// setValues returns the string values of a validated config, null values
+// remove keys and aren't included
+func setValues(config map[string]interface{}) map[string]string {
+	res := make(map[string]string)
+	for k, v := range config {
+		if s, ok := v.(string); ok {
+			res[k] = s
+		}
+	}
+	return res
+}
+
+// nullValues returns the keys of a validated config that are removed
+func nullValues(config map[string]interface{}) []string {
+	res := make([]string, 0)
+	for k, v := range config {
+		if v == nil {
+			res = append(res, k)
+		}
+	}
+	return res
 }
 
 func DeleteTopic(w http.ResponseWriter, r *http.Request) {
@@ -219,3 +299,39 @@ func Partitions(w http.ResponseWriter, r *http.Request) {
 	}
 	writeAsJson(w, Page(ps, p, topic.Partitions))
 }
+
+func TopicRacks(w http.ResponseWriter, r *http.Request) {
+	var (
+		name = pat.Param(r, "name")
+		user = r.Context().Value("user").(mw.SessionUser)
+	)
+	if !user.Permissions.DescribeTopic(name) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	topic, ok := store.Topic(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeAsJson(w, store.TopicRacks(topic, store.Racks()))
+}
+
+func Racks(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(mw.SessionUser)
+	if !user.Permissions.ListBrokers() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	reports := make([]store.TopicRackReport, 0)
+	for name, report := range store.RackReports() {
+		if user.Permissions.DescribeTopic(name) {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Topic < reports[j].Topic })
+	writeAsJson(w, map[string]interface{}{
+		"racks":  store.Racks(),
+		"topics": reports,
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/admin"
 	mw "github.com/cloudkarafka/cloudkarafka-manager/server/middleware"
-	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
 	"goji.io/pat"
 )
 
@@ -20,10 +20,10 @@ func Users(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	users, err := zookeeper.Users(user.Username, user.Permissions)
+	users, err := admin.Users(user.Username, user.Permissions)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[INFO] api.Users: %s", err)
-		http.Error(w, "Could not retrive user in ZooKeeper", http.StatusInternalServerError)
+		http.Error(w, "Could not retrive users", http.StatusInternalServerError)
 		return
 	}
 	/*
@@ -54,7 +54,7 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Cannot parse request body", http.StatusBadRequest)
 		return
 	}
-	err = zookeeper.CreateUser(form["name"], form["password"])
+	err = admin.CreateUser(form["name"], form["password"])
 	if err != nil {
 		w.Header().Add("Content-type", "text/plain")
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -70,10 +70,10 @@ func User(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	name := pat.Param(r, "name")
-	user, err := zookeeper.PermissionsFor(name)
+	user, err := admin.PermissionsFor(name)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] api.User: %s", err)
-		http.Error(w, "Couldn't get info from zookeeper", http.StatusInternalServerError)
+		http.Error(w, "Couldn't get user info", http.StatusInternalServerError)
 		return
 	}
 	writeAsJson(w, user)
@@ -87,7 +87,7 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 	name := pat.Param(r, "name")
 	if name != "admin" {
-		zookeeper.DeleteUser(name)
+		admin.DeleteUser(name)
 		fmt.Printf("[INFO] action=delete-user user=%s\n", name)
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/admin"
 	"github.com/cloudkarafka/cloudkarafka-manager/config"
 	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
 )
@@ -48,7 +49,7 @@ func SecureApi(h http.Handler) http.Handler {
 			}
 		case "scram":
 			if username != "" && password != "" && zookeeper.ValidateScramLogin(username, password) {
-				p, err := zookeeper.PermissionsFor(username)
+				p, err := admin.PermissionsFor(username)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "[ERROR] Secure middleware: %s\n", err)
 					http.Error(w, "Couldn't get user info from Zookeeper", http.StatusInternalServerError)
@@ -0,0 +1,276 @@
+package validators
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Violation is a topic that breaks a policy. Errors stop the request,
+// warnings are returned with the response.
+type Violation struct {
+	Policy   string `json:"policy"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+// NamingRule requires topics starting with Prefix to match Pattern, empty
+// prefix applies to all topics. Team is who owns the prefix and is shown
+// in violations.
+type NamingRule struct {
+	Prefix  string `json:"prefix"`
+	Team    string `json:"team,omitempty"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// ForbiddenConfig rejects a config value, e.g. retention.ms=-1
+type ForbiddenConfig struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Message string `json:"message,omitempty"`
+}
+
+// TopicPolicy is loaded from the JSON file given by --topic-policies, e.g.
+//
+//	{"naming": [{"prefix": "payments.", "team": "payments", "pattern": "^payments\\.[a-z-]+$"}],
+//	 "require_prefix": true, "min_partitions": 3, "max_partitions": 100,
+//	 "min_replication_factor": 3, "min_insync_replicas": 2,
+//	 "forbidden_configs": [{"name": "retention.ms", "value": "-1"}],
+//	 "require_owner": true, "required_labels": ["cost-center"]}
+//
+// Zero values disable a check. Topics with a prefix that has a team must
+// be owned by that team.
+type TopicPolicy struct {
+	Naming               []NamingRule      `json:"naming"`
+	RequirePrefix        bool              `json:"require_prefix"`
+	MinPartitions        int               `json:"min_partitions"`
+	MaxPartitions        int               `json:"max_partitions"`
+	MinReplicationFactor int               `json:"min_replication_factor"`
+	MinInsyncReplicas    int               `json:"min_insync_replicas"`
+	ForbiddenConfigs     []ForbiddenConfig `json:"forbidden_configs"`
+	RequireOwner         bool              `json:"require_owner"`
+	RequiredLabels       []string          `json:"required_labels"`
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     TopicPolicy
+)
+
+func LoadTopicPolicy(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var p TopicPolicy
+	if err = json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("Topic policies %s: %s", path, err)
+	}
+	for i, r := range p.Naming {
+		if p.Naming[i].re, err = regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("Topic policies %s, naming rule %q: %s", path, r.Prefix, err)
+		}
+	}
+	if p.MaxPartitions > 0 && p.MinPartitions > p.MaxPartitions {
+		return fmt.Errorf("Topic policies %s: min_partitions is larger than max_partitions", path)
+	}
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	policy = p
+	return nil
+}
+
+func Policy() TopicPolicy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return policy
+}
+
+// TopicRequest is a topic being created or updated, Partitions and
+// ReplicationFactor are 0 when not changed. Config holds the values being
+// set, Delete the configs reverting to the broker default and Append the
+// values added to list configs. Owner and Labels come from the topic's tags.
+type TopicRequest struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	Config            map[string]string
+	Delete            []string
+	Append            map[string]string
+	Owner             string
+	Labels            map[string]string
+}
+
+// CheckCreate checks a new topic against the policy, existing is the names
+// of the topics in the cluster
+func (p TopicPolicy) CheckCreate(t TopicRequest, existing []string) []Violation {
+	res := p.checkName(t.Name)
+	res = append(res, p.checkPartitions(t.Partitions)...)
+	if p.MinReplicationFactor > 0 && t.ReplicationFactor < p.MinReplicationFactor {
+		res = append(res, Violation{"min_replication_factor", "replication_factor",
+			fmt.Sprintf("must be at least %d", p.MinReplicationFactor), SeverityError})
+	}
+	if _, ok := t.Config["min.insync.replicas"]; !ok && p.MinInsyncReplicas > 0 {
+		res = append(res, Violation{"min_insync_replicas", "config.min.insync.replicas",
+			fmt.Sprintf("must be set to at least %d", p.MinInsyncReplicas), SeverityError})
+	}
+	res = append(res, p.checkConfig(t.Config, t.ReplicationFactor)...)
+	res = append(res, p.CheckTags(t)...)
+	res = append(res, collisions(t.Name, existing)...)
+	return res
+}
+
+// CheckTags checks the owner and labels of a topic
+func (p TopicPolicy) CheckTags(t TopicRequest) []Violation {
+	res := make([]Violation, 0)
+	if p.RequireOwner && t.Owner == "" {
+		res = append(res, Violation{"require_owner", "tags.owner", "is required", SeverityError})
+	}
+	for _, r := range p.Naming {
+		if r.Team != "" && t.Owner != "" && t.Owner != r.Team && strings.HasPrefix(t.Name, r.Prefix) {
+			res = append(res, Violation{"naming", "tags.owner",
+				fmt.Sprintf("topics starting with %s belong to %s", r.Prefix, r.Team), SeverityError})
+		}
+	}
+	for _, l := range p.RequiredLabels {
+		if t.Labels[l] == "" {
+			res = append(res, Violation{"required_labels", "tags.labels." + l, "is required", SeverityError})
+		}
+	}
+	return res
+}
+
+// CheckUpdate checks changes to an existing topic, replicationFactor is
+// that of the topic
+func (p TopicPolicy) CheckUpdate(t TopicRequest, replicationFactor int) []Violation {
+	res := make([]Violation, 0)
+	if t.Partitions > 0 {
+		res = append(res, p.checkPartitions(t.Partitions)...)
+	}
+	for _, name := range t.Delete {
+		// The broker default can't be checked, so don't allow falling back to it
+		if name == "min.insync.replicas" && p.MinInsyncReplicas > 0 {
+			res = append(res, Violation{"min_insync_replicas", "config.min.insync.replicas",
+				fmt.Sprintf("can't be removed, must be set to at least %d", p.MinInsyncReplicas), SeverityError})
+		}
+	}
+	for _, f := range p.ForbiddenConfigs {
+		if v, ok := t.Append[f.Name]; ok && v == f.Value {
+			res = append(res, forbidden(f))
+		}
+	}
+	return append(res, p.checkConfig(t.Config, replicationFactor)...)
+}
+
+func (p TopicPolicy) checkName(name string) []Violation {
+	var (
+		res     = make([]Violation, 0)
+		matched = false
+	)
+	for _, r := range p.Naming {
+		if !strings.HasPrefix(name, r.Prefix) {
+			continue
+		}
+		matched = true
+		if r.re != nil && !r.re.MatchString(name) {
+			msg := fmt.Sprintf("must match %s", r.Pattern)
+			if r.Team != "" {
+				msg += fmt.Sprintf(" for topics of %s", r.Team)
+			}
+			res = append(res, Violation{"naming", "name", msg, SeverityError})
+		}
+	}
+	if !matched && p.RequirePrefix && len(p.Naming) > 0 {
+		prefixes := make([]string, len(p.Naming))
+		for i, r := range p.Naming {
+			prefixes[i] = r.Prefix
+		}
+		res = append(res, Violation{"naming", "name",
+			"must start with one of " + strings.Join(prefixes, ", "), SeverityError})
+	}
+	return res
+}
+
+func (p TopicPolicy) checkPartitions(n int) []Violation {
+	if p.MinPartitions > 0 && n < p.MinPartitions {
+		return []Violation{{"min_partitions", "partitions",
+			fmt.Sprintf("must be at least %d", p.MinPartitions), SeverityError}}
+	}
+	if p.MaxPartitions > 0 && n > p.MaxPartitions {
+		return []Violation{{"max_partitions", "partitions",
+			fmt.Sprintf("must be at most %d", p.MaxPartitions), SeverityError}}
+	}
+	return nil
+}
+
+func (p TopicPolicy) checkConfig(config map[string]string, replicationFactor int) []Violation {
+	res := make([]Violation, 0)
+	if v, ok := config["min.insync.replicas"]; ok {
+		n, _ := strconv.Atoi(v)
+		if p.MinInsyncReplicas > 0 && n < p.MinInsyncReplicas {
+			res = append(res, Violation{"min_insync_replicas", "config.min.insync.replicas",
+				fmt.Sprintf("must be at least %d", p.MinInsyncReplicas), SeverityError})
+		}
+		// Producers with acks=all could never write to the topic
+		if replicationFactor > 0 && n > replicationFactor {
+			res = append(res, Violation{"min_insync_replicas", "config.min.insync.replicas",
+				fmt.Sprintf("can't be larger than the replication factor %d", replicationFactor), SeverityError})
+		}
+	}
+	for _, f := range p.ForbiddenConfigs {
+		if v, ok := config[f.Name]; ok && v == f.Value {
+			res = append(res, forbidden(f))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Field < res[j].Field })
+	return res
+}
+
+func forbidden(f ForbiddenConfig) Violation {
+	msg := f.Message
+	if msg == "" {
+		msg = fmt.Sprintf("%s isn't allowed", f.Value)
+	}
+	return Violation{"forbidden_configs", "config." + f.Name, msg, SeverityError}
+}
+
+// Kafka uses '.' and '_' in the same place in metric names, so topics that
+// differ only by those collide there
+func collisions(name string, existing []string) []Violation {
+	res := make([]Violation, 0)
+	key := strings.ReplaceAll(name, ".", "_")
+	for _, e := range existing {
+		if e != name && strings.ReplaceAll(e, ".", "_") == key {
+			res = append(res, Violation{"collision", "name",
+				fmt.Sprintf("collides with %s in metric names, they differ only by '.' and '_'", e), SeverityWarning})
+		}
+	}
+	return res
+}
+
+// Errors returns the violations that should stop the request
+func Errors(vs []Violation) []Violation {
+	res := make([]Violation, 0)
+	for _, v := range vs {
+		if v.Severity == SeverityError {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package validators
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func loadPolicy(t *testing.T, data string) TopicPolicy {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadTopicPolicy(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { policy = TopicPolicy{} })
+	return Policy()
+}
+
+func policies(vs []Violation) map[string]string {
+	res := make(map[string]string)
+	for _, v := range vs {
+		res[v.Policy] = v.Severity
+	}
+	return res
+}
+
+func TestCheckCreate(t *testing.T) {
+	p := loadPolicy(t, `{
+		"naming": [{"prefix": "payments.", "team": "payments", "pattern": "^payments\\.[a-z]+$"}],
+		"require_prefix": true, "min_partitions": 3, "max_partitions": 12,
+		"min_replication_factor": 3, "min_insync_replicas": 2,
+		"forbidden_configs": [{"name": "retention.ms", "value": "-1"}]}`)
+	vs := p.CheckCreate(TopicRequest{
+		Name:              "payments.Orders",
+		Partitions:        1,
+		ReplicationFactor: 2,
+		Config:            map[string]string{"retention.ms": "-1", "min.insync.replicas": "3"},
+	}, []string{"payments_Orders"})
+	expected := map[string]string{
+		"naming":                 SeverityError,
+		"min_partitions":         SeverityError,
+		"min_replication_factor": SeverityError,
+		"min_insync_replicas":    SeverityError,
+		"forbidden_configs":      SeverityError,
+		"collision":              SeverityWarning,
+	}
+	got := policies(vs)
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("Expected %s %s, got %v", v, k, vs)
+		}
+	}
+	vs = p.CheckCreate(TopicRequest{
+		Name:              "payments.orders",
+		Partitions:        3,
+		ReplicationFactor: 3,
+		Config:            map[string]string{"min.insync.replicas": "2"},
+	}, []string{"payments.refunds"})
+	if len(vs) != 0 {
+		t.Errorf("Expected no violations, got %v", vs)
+	}
+	if vs = p.CheckCreate(TopicRequest{Name: "orders", Partitions: 3, ReplicationFactor: 3,
+		Config: map[string]string{"min.insync.replicas": "2"}}, nil); len(Errors(vs)) != 1 {
+		t.Errorf("Expected a required prefix violation, got %v", vs)
+	}
+}
+
+func TestLoadTopicPolicyInvalidPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	ioutil.WriteFile(path, []byte(`{"naming": [{"prefix": "a", "pattern": "("}]}`), 0600)
+	if err := LoadTopicPolicy(path); err == nil {
+		t.Error("Expected invalid pattern to fail")
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	p := loadPolicy(t, `{
+		"naming": [{"prefix": "payments.", "team": "payments", "pattern": "^payments\\..+$"}],
+		"require_owner": true, "required_labels": ["cost-center"]}`)
+	vs := p.CheckTags(TopicRequest{Name: "payments.orders"})
+	if got := policies(vs); got["require_owner"] == "" || got["required_labels"] == "" {
+		t.Errorf("Expected owner and label violations, got %v", vs)
+	}
+	vs = p.CheckTags(TopicRequest{Name: "payments.orders", Owner: "search",
+		Labels: map[string]string{"cost-center": "42"}})
+	if len(vs) != 1 || vs[0].Field != "tags.owner" {
+		t.Errorf("Expected the prefix team to be enforced, got %v", vs)
+	}
+	vs = p.CheckTags(TopicRequest{Name: "payments.orders", Owner: "payments",
+		Labels: map[string]string{"cost-center": "42"}})
+	if len(vs) != 0 {
+		t.Errorf("Expected no violations, got %v", vs)
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	p := loadPolicy(t, `{"min_insync_replicas": 2,
+		"forbidden_configs": [{"name": "cleanup.policy", "value": "compact"}]}`)
+	vs := p.CheckUpdate(TopicRequest{
+		Name:   "orders",
+		Delete: []string{"min.insync.replicas"},
+		Append: map[string]string{"cleanup.policy": "compact"},
+	}, 3)
+	if got := policies(vs); got["min_insync_replicas"] == "" || got["forbidden_configs"] == "" {
+		t.Errorf("Expected delete and append violations, got %v", vs)
+	}
+	vs = p.CheckUpdate(TopicRequest{Name: "orders", Delete: []string{"retention.ms"},
+		Append: map[string]string{"cleanup.policy": "delete"}}, 3)
+	if len(vs) != 0 {
+		t.Errorf("Expected no violations, got %v", vs)
+	}
+}
@@ -0,0 +1,276 @@
+package validators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeBoolean = "boolean"
+	TypeInt     = "int"
+	TypeLong    = "long"
+	TypeDouble  = "double"
+	TypeString  = "string"
+	TypeList    = "list"
+)
+
+// ConfigSpec describes a topic config as Kafka defines it. Min and Max are
+// inclusive and nil when unbounded, Enum lists the valid values of string
+// configs and the valid items of list configs. Since is the Kafka version
+// that introduced the config. Dynamic configs can be changed on a live
+// topic.
+type ConfigSpec struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Default    string   `json:"default"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Enum       []string `json:"enum,omitempty"`
+	Since      string   `json:"since"`
+	Dynamic    bool     `json:"dynamic"`
+	Deprecated bool     `json:"deprecated,omitempty"`
+	Doc        string   `json:"doc"`
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+var maxLong = strconv.FormatInt(math.MaxInt64, 10)
+
+var TopicConfigs = []ConfigSpec{
+	{Name: "cleanup.policy", Type: TypeList, Default: "delete", Enum: []string{"delete", "compact"},
+		Since: "0.8.1", Dynamic: true, Doc: "Delete old segments, compact to the latest value per key, or both"},
+	{Name: "compression.type", Type: TypeString, Default: "producer",
+		Enum:  []string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"},
+		Since: "0.8.2", Dynamic: true, Doc: "Compression of the stored batches, producer keeps what the producer used"},
+	{Name: "delete.retention.ms", Type: TypeLong, Default: "86400000", Min: bound(0),
+		Since: "0.9.0", Dynamic: true, Doc: "How long delete tombstones are kept on compacted topics"},
+	{Name: "file.delete.delay.ms", Type: TypeLong, Default: "60000", Min: bound(0),
+		Since: "0.10.0", Dynamic: true, Doc: "Time to wait before deleting a file from the filesystem"},
+	{Name: "flush.messages", Type: TypeLong, Default: maxLong, Min: bound(1),
+		Since: "0.8.1", Dynamic: true, Doc: "Messages written before the log is forced to disk"},
+	{Name: "flush.ms", Type: TypeLong, Default: maxLong, Min: bound(0),
+		Since: "0.8.1", Dynamic: true, Doc: "Time before the log is forced to disk"},
+	{Name: "follower.replication.throttled.replicas", Type: TypeList, Default: "",
+		Since: "0.10.1", Dynamic: true, Doc: "partition:broker pairs, or *, throttled on the follower side"},
+	{Name: "index.interval.bytes", Type: TypeInt, Default: "4096", Min: bound(0),
+		Since: "0.8.1", Dynamic: true, Doc: "Bytes between offset index entries"},
+	{Name: "leader.replication.throttled.replicas", Type: TypeList, Default: "",
+		Since: "0.10.1", Dynamic: true, Doc: "partition:broker pairs, or *, throttled on the leader side"},
+	{Name: "local.retention.bytes", Type: TypeLong, Default: "-2", Min: bound(-2),
+		Since: "3.6.0", Dynamic: true, Doc: "Bytes kept locally with tiered storage, -2 uses retention.bytes"},
+	{Name: "local.retention.ms", Type: TypeLong, Default: "-2", Min: bound(-2),
+		Since: "3.6.0", Dynamic: true, Doc: "Time segments are kept locally with tiered storage, -2 uses retention.ms"},
+	{Name: "max.compaction.lag.ms", Type: TypeLong, Default: maxLong, Min: bound(1),
+		Since: "2.3.0", Dynamic: true, Doc: "Longest time a message stays ineligible for compaction"},
+	{Name: "max.message.bytes", Type: TypeInt, Default: "1048588", Min: bound(0),
+		Since: "0.8.1", Dynamic: true, Doc: "Largest record batch size allowed"},
+	{Name: "message.downconversion.enable", Type: TypeBoolean, Default: "true",
+		Since: "2.0.0", Dynamic: true, Doc: "Down-convert messages for consumers using old fetch versions"},
+	{Name: "message.format.version", Type: TypeString, Default: "3.0-IV1",
+		Since: "0.10.0", Dynamic: true, Deprecated: true, Doc: "Ignored from Kafka 3.0"},
+	{Name: "message.timestamp.after.max.ms", Type: TypeLong, Default: maxLong, Min: bound(0),
+		Since: "3.6.0", Dynamic: true, Doc: "How far a CreateTime timestamp may be ahead of the broker time"},
+	{Name: "message.timestamp.before.max.ms", Type: TypeLong, Default: maxLong, Min: bound(0),
+		Since: "3.6.0", Dynamic: true, Doc: "How far a CreateTime timestamp may be behind the broker time"},
+	{Name: "message.timestamp.difference.max.ms", Type: TypeLong, Default: maxLong, Min: bound(0),
+		Since: "0.10.0", Dynamic: true, Deprecated: true, Doc: "Replaced by message.timestamp.before/after.max.ms"},
+	{Name: "message.timestamp.type", Type: TypeString, Default: "CreateTime", Enum: []string{"CreateTime", "LogAppendTime"},
+		Since: "0.10.0", Dynamic: true, Doc: "Use the producer's timestamp or the broker's append time"},
+	{Name: "min.cleanable.dirty.ratio", Type: TypeDouble, Default: "0.5", Min: bound(0), Max: bound(1),
+		Since: "0.8.1", Dynamic: true, Doc: "Share of the log that must be uncompacted before cleaning"},
+	{Name: "min.compaction.lag.ms", Type: TypeLong, Default: "0", Min: bound(0),
+		Since: "0.10.1", Dynamic: true, Doc: "Shortest time a message stays uncompacted"},
+	{Name: "min.insync.replicas", Type: TypeInt, Default: "1", Min: bound(1),
+		Since: "0.8.2", Dynamic: true, Doc: "Replicas that must acknowledge a write with acks=all"},
+	{Name: "preallocate", Type: TypeBoolean, Default: "false",
+		Since: "0.9.0", Dynamic: true, Doc: "Preallocate the file when creating a segment"},
+	{Name: "remote.storage.enable", Type: TypeBoolean, Default: "false",
+		Since: "3.6.0", Dynamic: true, Doc: "Enable tiered storage, older brokers can't disable it again"},
+	{Name: "retention.bytes", Type: TypeLong, Default: "-1", Min: bound(-1),
+		Since: "0.8.1", Dynamic: true, Doc: "Largest size of a partition before old segments are deleted, -1 for no limit"},
+	{Name: "retention.ms", Type: TypeLong, Default: "604800000", Min: bound(-1),
+		Since: "0.8.1", Dynamic: true, Doc: "How long messages are kept, -1 for no limit"},
+	{Name: "segment.bytes", Type: TypeInt, Default: "1073741824", Min: bound(14),
+		Since: "0.8.1", Dynamic: true, Doc: "Size of a log segment"},
+	{Name: "segment.index.bytes", Type: TypeInt, Default: "10485760", Min: bound(4),
+		Since: "0.8.1", Dynamic: true, Doc: "Size of the offset index of a segment"},
+	{Name: "segment.jitter.ms", Type: TypeLong, Default: "0", Min: bound(0),
+		Since: "0.9.0", Dynamic: true, Doc: "Random jitter subtracted from segment.ms"},
+	{Name: "segment.ms", Type: TypeLong, Default: "604800000", Min: bound(1),
+		Since: "0.8.1", Dynamic: true, Doc: "Time before a segment is rolled even if it isn't full"},
+	{Name: "unclean.leader.election.enable", Type: TypeBoolean, Default: "false",
+		Since: "0.8.2", Dynamic: true, Doc: "Allow out of sync replicas to become leader, at the risk of data loss"},
+}
+
+func TopicConfigSpec(name string) (ConfigSpec, bool) {
+	for _, s := range TopicConfigs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return ConfigSpec{}, false
+}
+
+// FieldError is a validation error of one field in a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate checks that value is valid for the config
+func (s ConfigSpec) Validate(value string) error {
+	var (
+		n   float64
+		err error
+	)
+	switch s.Type {
+	case TypeBoolean:
+		if v := strings.ToLower(value); v != "true" && v != "false" {
+			return fmt.Errorf("must be true or false")
+		}
+		return nil
+	case TypeInt:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 32)
+		n = float64(i)
+	case TypeLong:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 64)
+		n = float64(i)
+	case TypeDouble:
+		n, err = strconv.ParseFloat(value, 64)
+	case TypeList:
+		if len(s.Enum) == 0 {
+			return nil
+		}
+		for _, item := range strings.Split(value, ",") {
+			if !contains(s.Enum, strings.TrimSpace(item)) {
+				return fmt.Errorf("%q isn't one of %s", strings.TrimSpace(item), strings.Join(s.Enum, ", "))
+			}
+		}
+		return nil
+	default:
+		if len(s.Enum) > 0 && !contains(s.Enum, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		return nil
+	}
+	if err != nil && s.Type == TypeDouble {
+		return fmt.Errorf("must be a number")
+	} else if err != nil {
+		return fmt.Errorf("must be a whole number in the range of a %s", s.Type)
+	}
+	if s.Min != nil && n < *s.Min {
+		return fmt.Errorf("must be at least %v", *s.Min)
+	}
+	if s.Max != nil && n > *s.Max {
+		return fmt.Errorf("must be at most %v", *s.Max)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTopicConfig checks keys and values of a topic config as sent in
+// requests. Values must be strings, null is allowed when deleting keys.
+func ValidateTopicConfig(config map[string]interface{}, allowNull bool) []FieldError {
+	errs := make([]FieldError, 0)
+	for k, v := range config {
+		field := "config." + k
+		spec, ok := TopicConfigSpec(k)
+		if !ok {
+			errs = append(errs, FieldError{field, unknownConfig(k)})
+			continue
+		}
+		switch v := v.(type) {
+		case nil:
+			if !allowNull {
+				errs = append(errs, FieldError{field, "must be a string"})
+			}
+		case string:
+			if err := spec.Validate(v); err != nil {
+				errs = append(errs, FieldError{field, err.Error()})
+			}
+		default:
+			errs = append(errs, FieldError{field, "must be a string"})
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// ValidateConfigOp checks an incremental config operation, append and
+// subtract only work on list configs
+func ValidateConfigOp(i int, op, name, value string) []FieldError {
+	field := fmt.Sprintf("operations[%d]", i)
+	spec, ok := TopicConfigSpec(name)
+	if !ok {
+		return []FieldError{{field + ".name", unknownConfig(name)}}
+	}
+	switch op {
+	case "delete":
+		return nil
+	case "append", "subtract":
+		if spec.Type != TypeList {
+			return []FieldError{{field + ".op", fmt.Sprintf("%s only works on list configs, %s is a %s", op, name, spec.Type)}}
+		}
+	}
+	if err := spec.Validate(value); err != nil {
+		return []FieldError{{field + ".value", err.Error()}}
+	}
+	return nil
+}
+
+// unknownConfig suggests the closest known config for typos
+func unknownConfig(name string) string {
+	var (
+		best     string
+		bestDist = 4
+	)
+	for _, s := range TopicConfigs {
+		if d := distance(name, s.Name); d < bestDist {
+			best, bestDist = s.Name, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown config, did you mean %s?", best)
+	}
+	return "unknown config"
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
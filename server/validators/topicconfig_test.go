@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTopicConfig(t *testing.T) {
+	errs := ValidateTopicConfig(map[string]interface{}{
+		"retention.ms":              "86400000",
+		"cleanup.policy":            "compact,delete",
+		"min.cleanable.dirty.ratio": "1.5",
+		"segment.bytes":             "10",
+		"compression.type":          "brotli",
+		"preallocate":               "yes",
+		"max.message.bytes":         1000,
+		"retention.msg":             "1",
+	}, false)
+	expected := map[string]string{
+		"config.compression.type":          "must be one of",
+		"config.max.message.bytes":         "must be a string",
+		"config.min.cleanable.dirty.ratio": "at most 1",
+		"config.preallocate":               "true or false",
+		"config.retention.msg":             "did you mean retention.ms?",
+		"config.segment.bytes":             "at least 14",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errs)
+	}
+	for _, e := range errs {
+		if !strings.Contains(e.Message, expected[e.Field]) {
+			t.Errorf("Unexpected error for %s: %s", e.Field, e.Message)
+		}
+	}
+}
+
+func TestValidateTopicConfigNull(t *testing.T) {
+	cfg := map[string]interface{}{"retention.ms": nil}
+	if errs := ValidateTopicConfig(cfg, true); len(errs) != 0 {
+		t.Errorf("Expected null to be allowed, got %v", errs)
+	}
+	if errs := ValidateTopicConfig(cfg, false); len(errs) != 1 {
+		t.Errorf("Expected null to be rejected, got %v", errs)
+	}
+}
+
+func TestValidateConfigOp(t *testing.T) {
+	if errs := ValidateConfigOp(0, "append", "cleanup.policy", "compact"); len(errs) != 0 {
+		t.Errorf("Expected append to a list to be valid, got %v", errs)
+	}
+	if errs := ValidateConfigOp(0, "append", "retention.ms", "1"); len(errs) != 1 || errs[0].Field != "operations[0].op" {
+		t.Errorf("Expected append to a long to be invalid, got %v", errs)
+	}
+	if errs := ValidateConfigOp(1, "set", "retention.ms", "-5"); len(errs) != 1 || errs[0].Field != "operations[1].value" {
+		t.Errorf("Expected value error, got %v", errs)
+	}
+}
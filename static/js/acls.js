@@ -24,10 +24,10 @@
     ckm.dom.formInput("input", "Principal", { placeholder: 'User:USERNAME' })
   )
   addBtn.insertAdjacentElement('beforebegin',
-    ckm.dom.formInput("select", "Resource type", ["Cluster", "Group", "Topic"])
+    ckm.dom.formInput("select", "Resource type", ["Cluster", "Group", "Topic", "TransactionalId", "DelegationToken"])
   )
   addBtn.insertAdjacentElement('beforebegin',
-    ckm.dom.formInput("select", "Permission", ["create", "describe", "alter", "read", "write", "alter_configs", "cluster_action", "delete", "describe", "describe_configs", "idempotent_write", "all"])
+    ckm.dom.formInput("select", "Permission", ["create", "describe", "alter", "read", "write", "alter_configs", "cluster_action", "delete", "describe", "describe_configs", "idempotent_write", "create_tokens", "describe_tokens", "all"])
   )
   addBtn.insertAdjacentElement('beforebegin',
     ckm.dom.formInput("select", "Permission type", ["Allow", "Deny"])
@@ -76,7 +76,7 @@ var (
 		"type":             "socket-server-metrics",
 		"listener":         "*",
 		"networkProcessor": "*"}}
-	// res, err := QueryBroker(brokerId, bean, "connection-count", "listener")
+	// Collected per listener through the metric catalogue
 
 	BeanAllTopicsBytesInPerSec = JMXBean{"kafka.server", map[string]string{
 		"type":  "BrokerTopicMetrics",
@@ -1,13 +1,12 @@
 package store
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudkarafka/cloudkarafka-manager/config"
-	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
 	humanize "github.com/dustin/go-humanize"
 )
 
@@ -21,6 +20,7 @@ type broker struct {
 	Host         string         `json:"host"`
 	Port         int            `json:"port"`
 	Id           int            `json:"id"`
+	Rack         string         `json:"rack"`
 	KafkaVersion string         `json:"kafka_version"`
 	Controller   bool           `json:"controller"`
 	Metrics      map[string]int `json:"metrics"`
@@ -28,6 +28,8 @@ type broker struct {
 	BytesOut     *SimpleTimeSerie
 	ISRShrink    *SimpleTimeSerie
 	ISRExpand    *SimpleTimeSerie
+	Series       *SeriesSet   `json:"-"`
+	Listeners    *SeriesGroup `json:"-"`
 }
 
 func (b broker) Online() bool {
@@ -39,12 +41,19 @@ func (b broker) Online() bool {
 	return false
 }
 func NewBroker() broker {
-	return broker{
+	b := broker{
 		BytesIn:   NewSimpleTimeSerie(5, MaxPoints),
 		BytesOut:  NewSimpleTimeSerie(5, MaxPoints),
 		ISRExpand: NewSimpleTimeSerie(5, MaxPoints),
 		ISRShrink: NewSimpleTimeSerie(5, MaxPoints),
+		Series:    NewSeriesSet(),
+		Listeners: NewSeriesGroup(),
 	}
+	b.Series.Series["bytes_in"] = b.BytesIn
+	b.Series.Series["bytes_out"] = b.BytesOut
+	b.Series.Series["isr_expand"] = b.ISRExpand
+	b.Series.Series["isr_shrink"] = b.ISRShrink
+	return b
 }
 
 func (b broker) Uptime() string {
@@ -56,17 +65,22 @@ func (b broker) Uptime() string {
 
 func fetchBroker(id int) (broker, error) {
 	b := NewBroker()
-	path := fmt.Sprintf("/brokers/ids/%d", id)
-	err := zookeeper.Get(path, &b)
+	md, err := metadata.Broker(id)
 	if err != nil {
 		return b, err
 	}
 	b.Id = id
+	b.JmxPort = md.JmxPort
+	b.Timestamp = md.Timestamp
+	b.Endpoints = md.Endpoints
+	b.Host = md.Host
+	b.Port = md.Port
+	b.Rack = md.Rack
 	b.Metrics = make(map[string]int)
-	if controller, err := zookeeper.Controller(); err != nil {
+	if controller, err := metadata.Controller(); err != nil {
 		return b, err
 	} else {
-		b.Controller = controller.BrokerId == id
+		b.Controller = controller == id
 	}
 	version, err := KafkaVersion(id)
 	if err != nil {
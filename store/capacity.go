@@ -0,0 +1,203 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// Kafka's default log.retention.hours, used for topics without override
+	DefaultRetention time.Duration = 7 * 24 * time.Hour
+	// Points of the bytes in series that make up the current ingest rate
+	capacityRecentPoints = 30
+)
+
+type TopicCapacity struct {
+	Name              string   `json:"name"`
+	Partitions        int      `json:"partitions"`
+	ReplicationFactor int      `json:"replication_factor"`
+	Size              int64    `json:"size"`
+	ReplicatedSize    int64    `json:"replicated_size"`
+	IngestRate        float64  `json:"ingest_bytes_per_sec"`
+	IngestTrend       float64  `json:"ingest_trend_percent"`
+	RetentionMs       int64    `json:"retention_ms"`
+	RetentionDefault  bool     `json:"retention_default"`
+	RetentionBytes    int64    `json:"retention_bytes"`
+	Compacted         bool     `json:"compacted"`
+	SteadyStateSize   *float64 `json:"steady_state_size"`
+	SteadyStateTotal  *float64 `json:"steady_state_replicated_size"`
+}
+
+type BrokerCapacity struct {
+	Broker          int      `json:"broker"`
+	Used            int64    `json:"used_bytes"`
+	Total           *int64   `json:"total_bytes"`
+	Usable          *int64   `json:"usable_bytes"`
+	SteadyStateUsed float64  `json:"steady_state_used_bytes"`
+	Headroom        *float64 `json:"headroom_bytes"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// CapacityReport totals are for the whole cluster, like the broker disks
+// they're compared with, even when Topics is filtered for a user
+type CapacityReport struct {
+	Generated        time.Time        `json:"generated"`
+	Size             int64            `json:"size"`
+	ReplicatedSize   int64            `json:"replicated_size"`
+	IngestRate       float64          `json:"ingest_bytes_per_sec"`
+	SteadyStateTotal float64          `json:"steady_state_replicated_size"`
+	Unbounded        []string         `json:"unbounded_topics"`
+	Topics           []TopicCapacity  `json:"topics"`
+	Brokers          []BrokerCapacity `json:"brokers"`
+}
+
+func configInt(t topic, key string) (int64, bool) {
+	v, ok := t.Config.Data[key]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+	return i, err == nil
+}
+
+// Mean of the latest points and the change compared to the mean of the
+// whole series, points before the first sample are zero and skipped
+func ingestRate(s TimeSerie) (float64, float64) {
+	if s == nil {
+		return 0, 0
+	}
+	var (
+		all         = s.All()
+		sum, recent float64
+		n, nRecent  int
+	)
+	for i, p := range all {
+		if p == 0 && n == 0 {
+			continue
+		}
+		sum += float64(p)
+		n++
+		if i >= len(all)-capacityRecentPoints {
+			recent += float64(p)
+			nRecent++
+		}
+	}
+	if nRecent == 0 {
+		return 0, 0
+	}
+	rate, mean := recent/float64(nRecent), sum/float64(n)
+	if mean == 0 {
+		return rate, 0
+	}
+	return rate, (rate - mean) / mean * 100
+}
+
+// topicCapacity estimates the size the topic settles at when retention
+// kicks in: retention time × ingest rate, capped by retention.bytes per
+// partition. Compacted topics and topics without time and size based
+// retention have no steady state.
+func topicCapacity(t topic) TopicCapacity {
+	tc := TopicCapacity{
+		Name:           t.Name,
+		Partitions:     len(t.Partitions),
+		RetentionMs:    DefaultRetention.Milliseconds(),
+		RetentionBytes: -1,
+	}
+	for _, p := range t.Partitions {
+		size := int64(p.Metrics["Size"])
+		tc.Size += size
+		tc.ReplicatedSize += size * int64(len(p.Replicas))
+		if len(p.Replicas) > tc.ReplicationFactor {
+			tc.ReplicationFactor = len(p.Replicas)
+		}
+	}
+	tc.IngestRate, tc.IngestTrend = ingestRate(t.BytesIn)
+	if v, ok := configInt(t, "retention.ms"); ok {
+		tc.RetentionMs = v
+	} else {
+		tc.RetentionDefault = true
+	}
+	if v, ok := configInt(t, "retention.bytes"); ok {
+		tc.RetentionBytes = v
+	}
+	if policy, ok := t.Config.Data["cleanup.policy"]; ok && fmt.Sprint(policy) == "compact" {
+		tc.Compacted = true
+		return tc
+	}
+	var steady float64 = -1
+	if tc.RetentionMs >= 0 {
+		steady = tc.IngestRate * float64(tc.RetentionMs) / 1000
+	}
+	if tc.RetentionBytes >= 0 {
+		limit := float64(tc.RetentionBytes) * float64(tc.Partitions)
+		if steady < 0 || limit < steady {
+			steady = limit
+		}
+	}
+	if steady >= 0 {
+		total := steady * float64(tc.ReplicationFactor)
+		tc.SteadyStateSize, tc.SteadyStateTotal = &steady, &total
+	}
+	return tc
+}
+
+// Capacity combines topic sizes and ingest rates with the disk usage of the
+// brokers. The steady state of a topic is assumed to spread evenly over its
+// partitions, headroom is only known for brokers that report usable bytes.
+func Capacity() CapacityReport {
+	var (
+		res = CapacityReport{
+			Generated: time.Now(),
+			Unbounded: make([]string, 0),
+			Topics:    make([]TopicCapacity, 0),
+			Brokers:   make([]BrokerCapacity, 0),
+		}
+		growth = make(map[int]float64)
+	)
+	for _, t := range Topics() {
+		tc := topicCapacity(t)
+		res.Topics = append(res.Topics, tc)
+		res.Size += tc.Size
+		res.ReplicatedSize += tc.ReplicatedSize
+		res.IngestRate += tc.IngestRate
+		if tc.SteadyStateTotal == nil {
+			res.Unbounded = append(res.Unbounded, tc.Name)
+			continue
+		}
+		res.SteadyStateTotal += *tc.SteadyStateTotal
+		if tc.Partitions > 0 {
+			perPartition := *tc.SteadyStateSize / float64(tc.Partitions)
+			for _, p := range t.Partitions {
+				for _, id := range p.Replicas {
+					growth[id] += perPartition - float64(p.Metrics["Size"])
+				}
+			}
+		}
+	}
+	sort.Slice(res.Topics, func(i, j int) bool { return res.Topics[i].ReplicatedSize > res.Topics[j].ReplicatedSize })
+	for _, d := range ClusterDiskUsage(0) {
+		bc := BrokerCapacity{Broker: d.Broker, Error: d.Error}
+		var total, usable int64
+		known := len(d.LogDirs) > 0
+		for _, l := range d.LogDirs {
+			bc.Used += l.UsedBytes
+			if l.Offline {
+				continue
+			}
+			if l.TotalBytes < 0 || l.UsableBytes < 0 {
+				known = false
+			}
+			total += l.TotalBytes
+			usable += l.UsableBytes
+		}
+		bc.SteadyStateUsed = float64(bc.Used) + growth[d.Broker]
+		if known {
+			headroom := float64(usable) - growth[d.Broker]
+			bc.Total, bc.Usable, bc.Headroom = &total, &usable, &headroom
+		}
+		res.Brokers = append(res.Brokers, bc)
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// bytesIn returns a series with a constant ingest rate after some points
+// before the first sample
+func bytesIn(rate int) *SimpleTimeSerie {
+	points := make([]int, 10+capacityRecentPoints)
+	for i := 10; i < len(points); i++ {
+		points[i] = rate
+	}
+	return &SimpleTimeSerie{interval: 5, Points: points}
+}
+
+func capacityTopic(name string, rate int, conf map[string]interface{}, replicas ...[]int) topic {
+	t := topic{Name: name, Config: TopicConfig{Data: conf}, BytesIn: bytesIn(rate)}
+	for i, r := range replicas {
+		t.Partitions = append(t.Partitions, Partition{Number: i, Replicas: r, Metrics: map[string]int{"Size": 100}})
+	}
+	return t
+}
+
+func TestIngestRate(t *testing.T) {
+	points := make([]int, 5+2*capacityRecentPoints)
+	for i := 5; i < len(points); i++ {
+		points[i] = 50
+		if i >= len(points)-capacityRecentPoints {
+			points[i] = 100
+		}
+	}
+	rate, trend := ingestRate(&SimpleTimeSerie{interval: 5, Points: points})
+	if rate != 100 {
+		t.Errorf("Expected rate 100, got %v", rate)
+	}
+	// the mean of the whole series, leading zeros skipped, is 75
+	if math.Abs(trend-100.0/3) > 1e-9 {
+		t.Errorf("Expected trend 33.3%%, got %v", trend)
+	}
+	if rate, trend = ingestRate(nil); rate != 0 || trend != 0 {
+		t.Errorf("Expected no rate without series, got %v and %v", rate, trend)
+	}
+}
+
+func steadyState(size float64) *float64 {
+	return &size
+}
+
+func TestTopicCapacity(t *testing.T) {
+	day := int64(24 * 60 * 60 * 1000)
+	tests := []struct {
+		name   string
+		conf   map[string]interface{}
+		steady *float64
+	}{
+		{"retention time", map[string]interface{}{"retention.ms": day}, steadyState(100 * 24 * 60 * 60)},
+		{"default retention", map[string]interface{}{}, steadyState(100 * DefaultRetention.Seconds())},
+		{"retention bytes cap", map[string]interface{}{"retention.ms": day, "retention.bytes": 1000}, steadyState(3000)},
+		{"retention bytes only", map[string]interface{}{"retention.ms": -1, "retention.bytes": 1000}, steadyState(3000)},
+		{"unbounded", map[string]interface{}{"retention.ms": -1, "retention.bytes": -1}, nil},
+		{"compacted", map[string]interface{}{"cleanup.policy": "compact"}, nil},
+	}
+	for _, tt := range tests {
+		tc := topicCapacity(capacityTopic("orders", 100, tt.conf, []int{1, 2}, []int{2, 3}, []int{3, 1}))
+		if tc.Partitions != 3 || tc.ReplicationFactor != 2 || tc.Size != 300 || tc.ReplicatedSize != 600 {
+			t.Errorf("%s: unexpected sizes %+v", tt.name, tc)
+		}
+		if tt.steady == nil {
+			if tc.SteadyStateSize != nil || tc.SteadyStateTotal != nil {
+				t.Errorf("%s: expected no steady state, got %v", tt.name, *tc.SteadyStateSize)
+			}
+			continue
+		}
+		if tc.SteadyStateSize == nil || *tc.SteadyStateSize != *tt.steady {
+			t.Errorf("%s: expected steady state %v, got %v", tt.name, *tt.steady, tc.SteadyStateSize)
+			continue
+		}
+		if *tc.SteadyStateTotal != 2*(*tt.steady) {
+			t.Errorf("%s: expected replicated steady state %v, got %v", tt.name, 2*(*tt.steady), *tc.SteadyStateTotal)
+		}
+	}
+}
+
+func seedCapacity(t *testing.T, topics []topic, brokerIds []int) {
+	urls := make(config.BrokerURLs)
+	for _, id := range brokerIds {
+		urls[id] = zookeeper.HostPort{Id: id, Host: "localhost", Port: 9092 + id}
+	}
+	prev := config.BrokerUrls
+	config.BrokerUrls = urls
+	for _, tp := range topics {
+		store.UpdateTopic(tp)
+	}
+	t.Cleanup(func() {
+		config.BrokerUrls = prev
+		store.Lock()
+		for _, tp := range topics {
+			delete(store.topics, tp.Name)
+		}
+		store.Unlock()
+		diskLock.Lock()
+		diskUsage = make(map[int]*brokerDisk)
+		diskLock.Unlock()
+	})
+}
+
+var errNoListener = errors.New("Broker 3 has no PLAINTEXT listener")
+
+func TestCapacityHeadroom(t *testing.T) {
+	// 2 partitions of 100 bytes settling at 500 bytes each
+	conf := map[string]interface{}{"retention.ms": -1, "retention.bytes": 500}
+	seedCapacity(t, []topic{
+		capacityTopic("orders", 100, conf, []int{1, 2}, []int{2, 1}),
+		capacityTopic("empty", 100, map[string]interface{}{}),
+	}, []int{1, 2, 3})
+	now := time.Now()
+	recordDiskUsage(1, []LogDir{{Path: "/data", TotalBytes: 10000, UsableBytes: 5000,
+		Partitions: []LogDirPartition{{Topic: "orders", Partition: 0, Size: 100}, {Topic: "orders", Partition: 1, Size: 100}}}}, now)
+	// v1 brokers don't report total and usable bytes
+	recordDiskUsage(2, []LogDir{{Path: "/data", TotalBytes: -1, UsableBytes: -1,
+		Partitions: []LogDirPartition{{Topic: "orders", Partition: 0, Size: 200}}}}, now)
+	recordDiskError(3, errNoListener)
+
+	res := Capacity()
+	if math.IsNaN(res.SteadyStateTotal) || res.SteadyStateTotal != 2000 {
+		t.Errorf("Expected steady state total 2000, got %v", res.SteadyStateTotal)
+	}
+	if len(res.Unbounded) != 0 {
+		t.Errorf("Expected no unbounded topics, got %v", res.Unbounded)
+	}
+	if len(res.Brokers) != 3 {
+		t.Fatalf("Expected 3 brokers, got %+v", res.Brokers)
+	}
+	b1, b2, b3 := res.Brokers[0], res.Brokers[1], res.Brokers[2]
+	if b1.Used != 200 || b1.SteadyStateUsed != 1000 {
+		t.Errorf("Broker 1: expected 200 used and 1000 at steady state, got %+v", b1)
+	}
+	if b1.Headroom == nil || *b1.Headroom != 4200 {
+		t.Errorf("Broker 1: expected headroom 4200, got %v", b1.Headroom)
+	}
+	if b2.Headroom != nil || b2.Total != nil || b2.SteadyStateUsed != 1000 {
+		t.Errorf("Broker 2: expected unknown headroom, got %+v", b2)
+	}
+	if b3.Error != errNoListener.Error() || b3.Headroom != nil {
+		t.Errorf("Broker 3: expected the disk error, got %+v", b3)
+	}
+}
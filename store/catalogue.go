@@ -0,0 +1,261 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// The metric catalogue declares which beans are collected from every
+// broker and where the values end up. It can be replaced with a JSON file
+// containing a list of entries, e.g.
+//
+//	[{"series": "produce_p99", "scope": "broker", "aggregation": "gauge",
+//	  "bean": "kafka.network:type=RequestMetrics,name=TotalTimeMs,request=Produce",
+//	  "attribute": "99thPercentile"}]
+//
+// Scopes are broker, topic, partition (one value per partition, from the
+// leader) and listener, beans for the last three must have topic, partition
+// or listener keys, usually with * as value. Aggregation is rate (per second
+// change of a counter), gauge (the value as is) or sum (sum of all beans
+// matching the wildcards). Topic values are summed over all brokers.
+
+const (
+	ScopeBroker    = "broker"
+	ScopeTopic     = "topic"
+	ScopePartition = "partition"
+	ScopeListener  = "listener"
+
+	AggregationRate  = "rate"
+	AggregationGauge = "gauge"
+	AggregationSum   = "sum"
+)
+
+type CatalogueEntry struct {
+	Series      string `json:"series"`
+	Bean        string `json:"bean"`
+	Attribute   string `json:"attribute"`
+	Scope       string `json:"scope"`
+	Aggregation string `json:"aggregation"`
+}
+
+var DefaultCatalogue = []CatalogueEntry{
+	{"bytes_in", "kafka.server:type=BrokerTopicMetrics,name=BytesInPerSec", "Count", ScopeBroker, AggregationRate},
+	{"bytes_out", "kafka.server:type=BrokerTopicMetrics,name=BytesOutPerSec", "Count", ScopeBroker, AggregationRate},
+	{"isr_expand", "kafka.server:type=ReplicaManager,name=IsrExpandsPerSec", "Count", ScopeBroker, AggregationRate},
+	{"isr_shrink", "kafka.server:type=ReplicaManager,name=IsrShrinksPerSec", "Count", ScopeBroker, AggregationRate},
+	{"bytes_in", "kafka.server:type=BrokerTopicMetrics,name=BytesInPerSec,topic=*", "Count", ScopeTopic, AggregationRate},
+	{"bytes_out", "kafka.server:type=BrokerTopicMetrics,name=BytesOutPerSec,topic=*", "Count", ScopeTopic, AggregationRate},
+	{"Size", "kafka.log:type=Log,name=Size,topic=*,partition=*", "Value", ScopePartition, AggregationGauge},
+	{"LogEndOffset", "kafka.log:type=Log,name=LogEndOffset,topic=*,partition=*", "Value", ScopePartition, AggregationGauge},
+	{"LogStartOffset", "kafka.log:type=Log,name=LogStartOffset,topic=*,partition=*", "Value", ScopePartition, AggregationGauge},
+	{"request_handler_idle", "kafka.server:type=KafkaRequestHandlerPool,name=RequestHandlerAvgIdlePercent", "OneMinuteRate", ScopeBroker, AggregationGauge},
+	{"network_processor_idle", "kafka.network:type=SocketServer,name=NetworkProcessorAvgIdlePercent", "Value", ScopeBroker, AggregationGauge},
+	{"produce_p50", "kafka.network:type=RequestMetrics,name=TotalTimeMs,request=Produce", "50thPercentile", ScopeBroker, AggregationGauge},
+	{"produce_p99", "kafka.network:type=RequestMetrics,name=TotalTimeMs,request=Produce", "99thPercentile", ScopeBroker, AggregationGauge},
+	{"fetch_consumer_p50", "kafka.network:type=RequestMetrics,name=TotalTimeMs,request=FetchConsumer", "50thPercentile", ScopeBroker, AggregationGauge},
+	{"fetch_consumer_p99", "kafka.network:type=RequestMetrics,name=TotalTimeMs,request=FetchConsumer", "99thPercentile", ScopeBroker, AggregationGauge},
+	{"under_replicated_partitions", "kafka.server:type=ReplicaManager,name=UnderReplicatedPartitions", "Value", ScopeBroker, AggregationGauge},
+	{"offline_partitions", "kafka.controller:type=KafkaController,name=OfflinePartitionsCount", "Value", ScopeBroker, AggregationGauge},
+	{"active_controller", "kafka.controller:type=KafkaController,name=ActiveControllerCount", "Value", ScopeBroker, AggregationGauge},
+	{"connection_count", "kafka.server:type=socket-server-metrics,listener=*,networkProcessor=*", "connection-count", ScopeListener, AggregationSum},
+	{"connection_creation_rate", "kafka.server:type=socket-server-metrics,listener=*,networkProcessor=*", "connection-creation-rate", ScopeListener, AggregationSum},
+	{"connection_close_rate", "kafka.server:type=socket-server-metrics,listener=*,networkProcessor=*", "connection-close-rate", ScopeListener, AggregationSum},
+	{"incoming_byte_rate", "kafka.server:type=socket-server-metrics,listener=*,networkProcessor=*", "incoming-byte-rate", ScopeListener, AggregationSum},
+	{"outgoing_byte_rate", "kafka.server:type=socket-server-metrics,listener=*,networkProcessor=*", "outgoing-byte-rate", ScopeListener, AggregationSum},
+}
+
+var catalogue = DefaultCatalogue
+
+// LoadCatalogue replaces the default catalogue with the entries in the file,
+// an empty path keeps the default
+func LoadCatalogue(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries []CatalogueEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("Metric catalogue %s: %s", path, err)
+	}
+	for i, e := range entries {
+		if err = e.Validate(); err != nil {
+			return fmt.Errorf("Metric catalogue %s, entry %d: %s", path, i, err)
+		}
+	}
+	catalogue = entries
+	return nil
+}
+
+func Catalogue() []CatalogueEntry {
+	return catalogue
+}
+
+func (e CatalogueEntry) Validate() error {
+	if e.Series == "" {
+		return fmt.Errorf("series is required")
+	}
+	if e.Attribute == "" {
+		return fmt.Errorf("attribute is required")
+	}
+	params, err := beanParams(e.Bean)
+	if err != nil {
+		return err
+	}
+	switch e.Aggregation {
+	case AggregationRate, AggregationGauge, AggregationSum:
+	default:
+		return fmt.Errorf("aggregation must be rate, gauge or sum, got %s", e.Aggregation)
+	}
+	required := map[string][]string{
+		ScopeBroker:    {},
+		ScopeTopic:     {"topic"},
+		ScopePartition: {"topic", "partition"},
+		ScopeListener:  {"listener"},
+	}
+	keys, ok := required[e.Scope]
+	if !ok {
+		return fmt.Errorf("scope must be broker, topic, partition or listener, got %s", e.Scope)
+	}
+	for _, k := range keys {
+		if _, ok := params[k]; !ok {
+			return fmt.Errorf("bean for scope %s must have the key %s", e.Scope, k)
+		}
+	}
+	if e.Scope == ScopePartition && e.Aggregation == AggregationRate {
+		return fmt.Errorf("rate isn't supported for partition scope")
+	}
+	return nil
+}
+
+func beanParams(bean string) (map[string]string, error) {
+	parts := strings.SplitN(bean, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("bean must be in the form domain:key=value,..., got %s", bean)
+	}
+	params := make(map[string]string)
+	for _, p := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bean must be in the form domain:key=value,..., got %s", bean)
+		}
+		params[kv[0]] = kv[1]
+	}
+	return params, nil
+}
+
+// Entries sharing bean and attribute result in one request
+type catalogueRequest struct {
+	MetricRequest
+	entries []CatalogueEntry
+}
+
+func catalogueRequests(hps []zookeeper.HostPort) []catalogueRequest {
+	var (
+		shared = make(map[string]int)
+		base   = make([]catalogueRequest, 0, len(catalogue))
+	)
+	for _, e := range catalogue {
+		key := e.Bean + "/" + e.Attribute
+		if i, ok := shared[key]; ok {
+			base[i].entries = append(base[i].entries, e)
+			continue
+		}
+		shared[key] = len(base)
+		base = append(base, catalogueRequest{
+			MetricRequest: MetricRequest{Bean: BeanFromString(e.Bean), Attr: e.Attribute},
+			entries:       []CatalogueEntry{e},
+		})
+	}
+	res := make([]catalogueRequest, 0, len(base)*len(hps))
+	for _, hp := range hps {
+		for _, r := range base {
+			r.BrokerId = hp.Id
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Sample is one value for a catalogue series, after aggregation
+type Sample struct {
+	Series      string
+	Scope       string
+	Aggregation string
+	Broker      int
+	Topic       string
+	Partition   string
+	Listener    string
+	Value       float64
+}
+
+func (s Sample) key() string {
+	switch s.Scope {
+	case ScopeTopic:
+		return s.Scope + "/" + s.Series + "/" + s.Topic
+	case ScopePartition:
+		return s.Scope + "/" + s.Series + "/" + s.Topic + "/" + s.Partition
+	case ScopeListener:
+		return s.Scope + "/" + s.Series + "/" + strconv.Itoa(s.Broker) + "/" + s.Listener
+	}
+	return s.Scope + "/" + s.Series + "/" + strconv.Itoa(s.Broker)
+}
+
+type sampleAggregator struct {
+	keys    []string
+	samples map[string]Sample
+}
+
+func newSampleAggregator() *sampleAggregator {
+	return &sampleAggregator{samples: make(map[string]Sample)}
+}
+
+func (me *sampleAggregator) add(e CatalogueEntry, m Metric) {
+	s := Sample{
+		Series:      e.Series,
+		Scope:       e.Scope,
+		Aggregation: e.Aggregation,
+		Broker:      m.Broker,
+		Value:       m.Value,
+	}
+	switch e.Scope {
+	case ScopeTopic:
+		if m.Topic == "" {
+			return
+		}
+		s.Topic = m.Topic
+	case ScopePartition:
+		if m.Topic == "" || m.Partition == "" || !isLeader(m.Topic, m.Partition, m.Broker) {
+			return
+		}
+		s.Topic, s.Partition = m.Topic, m.Partition
+	case ScopeListener:
+		if m.Listener == "" {
+			return
+		}
+		s.Listener = m.Listener
+	}
+	key := s.key()
+	prev, ok := me.samples[key]
+	if !ok {
+		me.keys = append(me.keys, key)
+	} else if e.Aggregation != AggregationGauge {
+		s.Value += prev.Value
+	}
+	me.samples[key] = s
+}
+
+func (me *sampleAggregator) result() []Sample {
+	res := make([]Sample, len(me.keys))
+	for i, k := range me.keys {
+		res[i] = me.samples[k]
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// Changes seen by the store are published as events. These are only
+// touched from the Start loop.
+var (
+	knownBrokers    map[int]bool
+	knownController = -1
+	topicsLoaded    bool
+)
+
+func publishBrokerChanges(hps []zookeeper.HostPort) {
+	current := make(map[int]bool)
+	for _, hp := range hps {
+		current[hp.Id] = true
+		if knownBrokers != nil && !knownBrokers[hp.Id] {
+			publish(events.BrokerJoined, strconv.Itoa(hp.Id), hp)
+		}
+	}
+	for id := range knownBrokers {
+		if !current[id] {
+			publish(events.BrokerLeft, strconv.Itoa(id), nil)
+		}
+	}
+	knownBrokers = current
+}
+
+// A broker that registers again in zookeeper gets a new timestamp. KRaft
+// metadata has no registration time, so restarts there only show up as the
+// broker leaving and joining when a poll happens while it's down.
+func publishBrokerRestart(prev, b broker) {
+	if prev.Timestamp == "" || b.Timestamp == "" || prev.Timestamp == b.Timestamp {
+		return
+	}
+	publish(events.BrokerRestarted, strconv.Itoa(b.Id), map[string]string{
+		"previous": prev.Timestamp,
+		"current":  b.Timestamp,
+	})
+}
+
+func publishControllerChange() {
+	id, err := metadata.Controller()
+	if err != nil || id == knownController {
+		return
+	}
+	if knownController != -1 {
+		publish(events.ControllerChanged, strconv.Itoa(id), map[string]int{
+			"previous": knownController,
+			"current":  id,
+		})
+	}
+	knownController = id
+}
+
+// publishTopicListChanges compares the topic list with the store, topics
+// that are gone are removed from the store
+func publishTopicListChanges(topics []zookeeper.T) {
+	current := make(map[string]bool)
+	for _, t := range topics {
+		current[t.Name] = true
+	}
+	for _, t := range store.Topics() {
+		if !current[t.Name] {
+			store.DeleteTopic(t.Name)
+			dropConfigHistory(t.Name)
+			dropTopicTags(t.Name)
+			publish(events.TopicDeleted, t.Name, nil)
+		}
+	}
+	if topicsLoaded {
+		for _, t := range topics {
+			if _, ok := store.Topic(t.Name); !ok {
+				publish(events.TopicCreated, t.Name, nil)
+			}
+		}
+	}
+	topicsLoaded = true
+}
+
+// publishTopicChanges compares a refetched topic with what the store had
+func publishTopicChanges(prev, t topic) {
+	if !reflect.DeepEqual(prev.Config.Data, t.Config.Data) {
+		recordConfigVersion(t.Name, configStrings(prev.Config.Data), configStrings(t.Config.Data), "", 0)
+		publish(events.TopicConfigChanged, t.Name, map[string]interface{}{
+			"previous": prev.Config.Data,
+			"current":  t.Config.Data,
+		})
+	}
+	for i, p := range t.Partitions {
+		if i < len(prev.Partitions) {
+			publishISRChange(t.Name, p.Number, prev.Partitions[i].ISR, p.ISR)
+		}
+	}
+}
+
+func publishISRChange(topic string, partition int, prev, isr []int) {
+	if reflect.DeepEqual(prev, isr) {
+		return
+	}
+	publish(events.ISRChanged, topic, map[string]interface{}{
+		"partition": partition,
+		"previous":  prev,
+		"current":   isr,
+	})
+}
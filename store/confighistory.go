@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+)
+
+const configHistoryFile = "config_history.json"
+
+// ConfigChange is one key that differs between two versions, Old or New
+// is nil when the key isn't set and the broker default applies
+type ConfigChange struct {
+	Key string  `json:"key"`
+	Old *string `json:"old"`
+	New *string `json:"new"`
+}
+
+// ConfigVersion is the topic config after a change. The first version of
+// a topic is the config as it was when the manager first saw it change,
+// changes made outside the manager are recorded without user when the
+// topics are refreshed.
+type ConfigVersion struct {
+	Version    int               `json:"version"`
+	Time       time.Time         `json:"time"`
+	User       string            `json:"user"`
+	RollbackOf int               `json:"rollback_of,omitempty"`
+	Config     map[string]string `json:"config"`
+	Changes    []ConfigChange    `json:"changes"`
+}
+
+var (
+	configHistoryLock   sync.Mutex
+	configHistory       map[string][]ConfigVersion
+	VersionDoesNotExist = fmt.Errorf("Version does not exist")
+)
+
+// loadConfigHistory reads the history the first time it's used, caller
+// must hold configHistoryLock
+func loadConfigHistory() {
+	if configHistory != nil {
+		return
+	}
+	configHistory = make(map[string][]ConfigVersion)
+	if err := LoadState(configHistoryFile, &configHistory); err != nil {
+		log.Error("load_config_history", log.ErrorEntry{err})
+	}
+}
+
+func configStrings(c map[string]interface{}) map[string]string {
+	res := make(map[string]string, len(c))
+	for k, v := range c {
+		res[k] = fmt.Sprint(v)
+	}
+	return res
+}
+
+// recordConfigVersion adds a version when config differs from the latest
+// version of the topic, prev is recorded as the first version
+func recordConfigVersion(name string, prev, config map[string]string, user string, rollbackOf int) {
+	configHistoryLock.Lock()
+	defer configHistoryLock.Unlock()
+	loadConfigHistory()
+	h := configHistory[name]
+	now := time.Now()
+	if len(h) == 0 {
+		if prev == nil {
+			prev = make(map[string]string)
+		}
+		h = append(h, ConfigVersion{Version: 1, Time: now, Config: prev, Changes: []ConfigChange{}})
+	}
+	last := h[len(h)-1]
+	changes := DiffConfig(last.Config, config)
+	if len(changes) == 0 {
+		return
+	}
+	configHistory[name] = append(h, ConfigVersion{
+		Version:    last.Version + 1,
+		Time:       now,
+		User:       user,
+		RollbackOf: rollbackOf,
+		Config:     config,
+		Changes:    changes,
+	})
+	if err := SaveState(configHistoryFile, configHistory); err != nil {
+		log.Error("save_config_history", log.ErrorEntry{err})
+	}
+}
+
+func dropConfigHistory(name string) {
+	configHistoryLock.Lock()
+	defer configHistoryLock.Unlock()
+	loadConfigHistory()
+	if _, ok := configHistory[name]; !ok {
+		return
+	}
+	delete(configHistory, name)
+	if err := SaveState(configHistoryFile, configHistory); err != nil {
+		log.Error("save_config_history", log.ErrorEntry{err})
+	}
+}
+
+// DiffConfig returns the keys that differ between a and b sorted on key
+func DiffConfig(a, b map[string]string) []ConfigChange {
+	res := make([]ConfigChange, 0)
+	for k, v := range a {
+		old := v
+		if n, ok := b[k]; !ok {
+			res = append(res, ConfigChange{Key: k, Old: &old})
+		} else if n != v {
+			res = append(res, ConfigChange{Key: k, Old: &old, New: &n})
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			n := v
+			res = append(res, ConfigChange{Key: k, New: &n})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// ConfigHistory returns all versions of the topic config, oldest first
+func ConfigHistory(name string) []ConfigVersion {
+	configHistoryLock.Lock()
+	defer configHistoryLock.Unlock()
+	loadConfigHistory()
+	res := make([]ConfigVersion, len(configHistory[name]))
+	copy(res, configHistory[name])
+	return res
+}
+
+// ConfigVersionOf returns one version of the topic config, version 0 is
+// the current config of the topic
+func ConfigVersionOf(name string, version int) (ConfigVersion, error) {
+	if version == 0 {
+		t, ok := store.Topic(name)
+		if !ok {
+			return ConfigVersion{}, VersionDoesNotExist
+		}
+		return ConfigVersion{Time: time.Now(), Config: configStrings(t.Config.Data)}, nil
+	}
+	for _, v := range ConfigHistory(name) {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return ConfigVersion{}, VersionDoesNotExist
+}
+
+// RollbackTopicConfig applies the operations from RollbackOps, taking them
+// as an argument so the operations that were checked are the ones applied
+func RollbackTopicConfig(ctx context.Context, name string, version int, ops []ConfigOp, user string) error {
+	return alterTopicConfig(ctx, name, ops, user, version)
+}
+
+// RollbackOps returns the operations that bring the topic config back to
+// the version
+func RollbackOps(name string, version int) ([]ConfigOp, error) {
+	v, err := ConfigVersionOf(name, version)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := store.Topic(name)
+	if !ok {
+		return nil, fmt.Errorf("Topic does not exist")
+	}
+	changes := DiffConfig(configStrings(t.Config.Data), v.Config)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("Topic config is already the same as version %d", version)
+	}
+	ops := make([]ConfigOp, len(changes))
+	for i, c := range changes {
+		if c.New == nil {
+			ops[i] = ConfigOp{Op: ConfigOpDelete, Name: c.Key}
+		} else {
+			ops[i] = ConfigOp{Op: ConfigOpSet, Name: c.Key, Value: *c.New}
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ListenerConnections is the latest sample of the socket-server-metrics of
+// one listener, summed over its network processors. Rates are per second.
+type ListenerConnections struct {
+	Listener     string  `json:"listener"`
+	Connections  float64 `json:"connections"`
+	CreationRate float64 `json:"creation_rate"`
+	CloseRate    float64 `json:"close_rate"`
+	BytesIn      float64 `json:"bytes_in"`
+	BytesOut     float64 `json:"bytes_out"`
+}
+
+func (me *ListenerConnections) add(o ListenerConnections) {
+	me.Connections += o.Connections
+	me.CreationRate += o.CreationRate
+	me.CloseRate += o.CloseRate
+	me.BytesIn += o.BytesIn
+	me.BytesOut += o.BytesOut
+}
+
+func listenerConnections(name string, s *SeriesSet) ListenerConnections {
+	value := func(series string) float64 {
+		v, _ := s.Value(series)
+		return v
+	}
+	return ListenerConnections{
+		Listener:     name,
+		Connections:  value("connection_count"),
+		CreationRate: value("connection_creation_rate"),
+		CloseRate:    value("connection_close_rate"),
+		BytesIn:      value("incoming_byte_rate"),
+		BytesOut:     value("outgoing_byte_rate"),
+	}
+}
+
+// BrokerConnections returns one entry per listener, sorted by name
+func BrokerConnections(b broker) []ListenerConnections {
+	res := make([]ListenerConnections, 0)
+	if b.Listeners == nil {
+		return res
+	}
+	for name, s := range b.Listeners.All() {
+		res = append(res, listenerConnections(name, s))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Listener < res[j].Listener })
+	return res
+}
+
+type ConnectionsReport struct {
+	Total     ListenerConnections              `json:"total"`
+	Listeners []ListenerConnections            `json:"listeners"`
+	Brokers   map[string][]ListenerConnections `json:"brokers"`
+}
+
+// ClusterConnections sums the listeners of all brokers, per listener name
+// and in total
+func ClusterConnections() ConnectionsReport {
+	var (
+		perListener = make(map[string]*ListenerConnections)
+		res         = ConnectionsReport{
+			Total:     ListenerConnections{Listener: "all"},
+			Listeners: make([]ListenerConnections, 0),
+			Brokers:   make(map[string][]ListenerConnections),
+		}
+	)
+	for _, b := range Brokers() {
+		lcs := BrokerConnections(b)
+		res.Brokers[strconv.Itoa(b.Id)] = lcs
+		for _, lc := range lcs {
+			sum, ok := perListener[lc.Listener]
+			if !ok {
+				sum = &ListenerConnections{Listener: lc.Listener}
+				perListener[lc.Listener] = sum
+			}
+			sum.add(lc)
+			res.Total.add(lc)
+		}
+	}
+	for _, lc := range perListener {
+		res.Listeners = append(res.Listeners, *lc)
+	}
+	sort.Slice(res.Listeners, func(i, j int) bool { return res.Listeners[i].Listener < res.Listeners[j].Listener })
+	return res
+}
@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+)
+
+const (
+	DiskSampleTime time.Duration = time.Minute
+	maxDiskPoints                = 360
+	// Projections further away than this aren't reported
+	maxDiskProjection time.Duration = 10 * 365 * 24 * time.Hour
+)
+
+type diskPoint struct {
+	time time.Time
+	used int64
+}
+
+type brokerDisk struct {
+	updated time.Time
+	err     string
+	dirs    []LogDir
+	history map[string][]diskPoint
+}
+
+var (
+	diskLock  sync.RWMutex
+	diskUsage = make(map[int]*brokerDisk)
+)
+
+// FetchDiskUsage describes the log dirs of every broker and records the
+// used bytes per log dir, the history is what growth rates are based on
+func FetchDiskUsage(brokerIds []int) {
+	for _, id := range brokerIds {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		dirs, err := DescribeLogDirs(ctx, id)
+		cancel()
+		if err != nil {
+			log.Error("disk_usage", log.ErrorEntry{err})
+			recordDiskError(id, err)
+			continue
+		}
+		recordDiskUsage(id, dirs, time.Now())
+	}
+}
+
+func brokerDiskState(brokerId int) *brokerDisk {
+	bd, ok := diskUsage[brokerId]
+	if !ok {
+		bd = &brokerDisk{history: make(map[string][]diskPoint)}
+		diskUsage[brokerId] = bd
+	}
+	return bd
+}
+
+// The last sample is kept when describing the log dirs fails, the error is
+// reported along with it until a sample succeeds again
+func recordDiskError(brokerId int, err error) {
+	diskLock.Lock()
+	defer diskLock.Unlock()
+	brokerDiskState(brokerId).err = err.Error()
+}
+
+func recordDiskUsage(brokerId int, dirs []LogDir, now time.Time) {
+	diskLock.Lock()
+	defer diskLock.Unlock()
+	bd := brokerDiskState(brokerId)
+	bd.updated = now
+	bd.err = ""
+	bd.dirs = dirs
+	for _, d := range dirs {
+		if d.Offline {
+			continue
+		}
+		h := append(bd.history[d.Path], diskPoint{now, d.Size()})
+		if len(h) > maxDiskPoints {
+			h = h[len(h)-maxDiskPoints:]
+		}
+		bd.history[d.Path] = h
+	}
+}
+
+// Least squares slope of used bytes over time, in bytes per second
+func growthRate(points []diskPoint) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	var (
+		t0               = points[0].time
+		n                = float64(len(points))
+		sx, sy, sxx, sxy float64
+	)
+	for _, p := range points {
+		x := p.time.Sub(t0).Seconds()
+		y := float64(p.used)
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / d
+}
+
+type LogDirUsage struct {
+	Path        string     `json:"path"`
+	Error       string     `json:"error,omitempty"`
+	Offline     bool       `json:"offline"`
+	TotalBytes  int64      `json:"total_bytes"`
+	UsableBytes int64      `json:"usable_bytes"`
+	UsedBytes   int64      `json:"used_bytes"`
+	Partitions  int        `json:"partitions"`
+	Growth      float64    `json:"growth_bytes_per_sec"`
+	FullAt      *time.Time `json:"full_at"`
+}
+
+type PartitionUsage struct {
+	LogDirPartition
+	Path string `json:"path"`
+}
+
+type DiskReport struct {
+	Broker        int              `json:"broker"`
+	Updated       time.Time        `json:"updated"`
+	Error         string           `json:"error,omitempty"`
+	LogDirs       []LogDirUsage    `json:"log_dirs"`
+	TopPartitions []PartitionUsage `json:"top_partitions"`
+}
+
+// BrokerDiskUsage reports the log dirs of the broker from the latest sample
+// with the top biggest partition replicas. The disk full date is projected
+// from the growth rate and the usable bytes, which only brokers supporting
+// DescribeLogDirs v4 report. Error is set when the latest sample failed.
+func BrokerDiskUsage(brokerId, top int) (DiskReport, bool) {
+	diskLock.RLock()
+	defer diskLock.RUnlock()
+	bd, ok := diskUsage[brokerId]
+	if !ok {
+		return DiskReport{}, false
+	}
+	res := DiskReport{
+		Broker:        brokerId,
+		Updated:       bd.updated,
+		Error:         bd.err,
+		LogDirs:       make([]LogDirUsage, len(bd.dirs)),
+		TopPartitions: make([]PartitionUsage, 0),
+	}
+	for i, d := range bd.dirs {
+		u := LogDirUsage{
+			Path:        d.Path,
+			Error:       d.Error,
+			Offline:     d.Offline,
+			TotalBytes:  d.TotalBytes,
+			UsableBytes: d.UsableBytes,
+			UsedBytes:   d.Size(),
+			Partitions:  len(d.Partitions),
+			Growth:      growthRate(bd.history[d.Path]),
+		}
+		if u.Growth > 0 && u.UsableBytes >= 0 {
+			left := float64(u.UsableBytes) / u.Growth * float64(time.Second)
+			if left < float64(maxDiskProjection) {
+				full := bd.updated.Add(time.Duration(left))
+				u.FullAt = &full
+			}
+		}
+		res.LogDirs[i] = u
+		for _, p := range d.Partitions {
+			res.TopPartitions = append(res.TopPartitions, PartitionUsage{p, d.Path})
+		}
+	}
+	sort.Slice(res.TopPartitions, func(i, j int) bool {
+		return res.TopPartitions[i].Size > res.TopPartitions[j].Size
+	})
+	if top >= 0 && len(res.TopPartitions) > top {
+		res.TopPartitions = res.TopPartitions[:top]
+	}
+	return res, true
+}
+
+// ClusterDiskUsage reports every broker that has been sampled
+func ClusterDiskUsage(top int) []DiskReport {
+	res := make([]DiskReport, 0)
+	for _, id := range config.BrokerUrls.IDs() {
+		if r, ok := BrokerDiskUsage(id, top); ok {
+			res = append(res, r)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Broker < res[j].Broker })
+	return res
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/patrickmn/go-cache"
+)
+
+// When the metrics plugin isn't running on a broker using the reporter
+// source, the partitions it leads get offsets from ListOffsets, sizes from
+// DescribeLogDirs and leader/ISR from the metadata API instead. Throughput
+// can't be derived this way.
+
+// Brokers are probed for the plugin at most this often, otherwise brokers
+// without it would be requested on every sample
+const pluginCheckInterval = time.Minute
+
+var pluginCache = cache.New(pluginCheckInterval, pluginCheckInterval)
+
+func PluginAvailable(brokerId int) bool {
+	key := strconv.Itoa(brokerId)
+	if v, found := pluginCache.Get(key); found {
+		return v.(bool)
+	}
+	v, err := PluginVersion(brokerId)
+	available := err == nil && v != ""
+	pluginCache.Set(key, available, cache.DefaultExpiration)
+	return available
+}
+
+func brokersWithoutPlugin(ids []int) map[int]bool {
+	res := make(map[int]bool)
+	for _, id := range ids {
+		if config.BrokerMetricSource(id) == "reporter" && !PluginAvailable(id) {
+			res[id] = true
+		}
+	}
+	return res
+}
+
+func FetchFallbackMetrics(ctx context.Context, metrics chan Metric, brokerIds []int) {
+	missing := brokersWithoutPlugin(brokerIds)
+	if len(missing) == 0 {
+		return
+	}
+	a, err := adminClient()
+	if err != nil {
+		log.Error("fallback_metrics", log.ErrorEntry{err})
+		return
+	}
+	defer a.Close()
+	topics := fallbackTopics(missing)
+	if len(topics) == 0 {
+		return
+	}
+	if err := refreshPartitionState(a, topics); err != nil {
+		log.Error("fallback_metrics", log.ErrorEntry{err})
+	}
+	if err := fetchOffsets(ctx, a, metrics, missing); err != nil {
+		log.Error("fallback_metrics", log.ErrorEntry{err})
+	}
+	for id := range missing {
+		if err := fetchLogDirSizes(ctx, metrics, id); err != nil {
+			log.Error("fallback_metrics", log.ErrorEntry{err})
+		}
+	}
+}
+
+// Topics that have at least one partition led by a broker without plugin
+func fallbackTopics(missing map[int]bool) []string {
+	res := make([]string, 0)
+	for _, t := range store.Topics() {
+		for _, p := range t.Partitions {
+			if missing[p.Leader] {
+				res = append(res, t.Name)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func refreshPartitionState(a *kafka.AdminClient, topics []string) error {
+	for _, name := range topics {
+		md, err := a.GetMetadata(&name, false, int(Timeout.Milliseconds()))
+		if err != nil {
+			return err
+		}
+		tm, ok := md.Topics[name]
+		if !ok || tm.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		for _, p := range tm.Partitions {
+			store.UpdatePartitionState(name, int(p.ID), int(p.Leader), int32s(p.Isrs))
+		}
+	}
+	return nil
+}
+
+func fetchOffsets(ctx context.Context, a *kafka.AdminClient, metrics chan Metric, missing map[int]bool) error {
+	var (
+		earliest = make(map[kafka.TopicPartition]kafka.OffsetSpec)
+		latest   = make(map[kafka.TopicPartition]kafka.OffsetSpec)
+		leaders  = make(map[string]int)
+	)
+	for _, t := range store.Topics() {
+		name := t.Name
+		for _, p := range t.Partitions {
+			if !missing[p.Leader] {
+				continue
+			}
+			tp := kafka.TopicPartition{Topic: &name, Partition: int32(p.Number)}
+			earliest[tp] = kafka.EarliestOffsetSpec
+			latest[tp] = kafka.LatestOffsetSpec
+			leaders[name+"/"+strconv.Itoa(p.Number)] = p.Leader
+		}
+	}
+	if len(earliest) == 0 {
+		return nil
+	}
+	for metric, spec := range map[string]map[kafka.TopicPartition]kafka.OffsetSpec{
+		"LogStartOffset": earliest,
+		"LogEndOffset":   latest,
+	} {
+		res, err := a.ListOffsets(ctx, spec)
+		if err != nil {
+			return err
+		}
+		for tp, info := range res.ResultInfos {
+			if info.Error.Code() != kafka.ErrNoError || tp.Topic == nil {
+				continue
+			}
+			partition := strconv.Itoa(int(tp.Partition))
+			m := Metric{
+				Broker:    leaders[*tp.Topic+"/"+partition],
+				Topic:     *tp.Topic,
+				Partition: partition,
+				Name:      metric,
+				Value:     float64(info.Offset),
+			}
+			select {
+			case metrics <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Sizes are only reported for the partitions the broker leads, same as the
+// JMX requests
+func fetchLogDirSizes(ctx context.Context, metrics chan Metric, brokerId int) error {
+	dirs, err := DescribeLogDirs(ctx, brokerId)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		for _, p := range d.Partitions {
+			if p.IsFuture {
+				continue
+			}
+			t, ok := store.Topic(p.Topic)
+			if !ok || len(t.Partitions) <= p.Partition || t.Partitions[p.Partition].Leader != brokerId {
+				continue
+			}
+			m := Metric{
+				Broker:    brokerId,
+				Topic:     p.Topic,
+				Partition: strconv.Itoa(p.Partition),
+				Name:      "Size",
+				Value:     float64(p.Size),
+			}
+			select {
+			case metrics <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func int32s(v []int32) []int {
+	res := make([]int, len(v))
+	for i, x := range v {
+		res[i] = int(x)
+	}
+	return res
+}
@@ -0,0 +1,148 @@
+package store
+
+import "strconv"
+
+const (
+	HealthGreen   = "green"
+	HealthYellow  = "yellow"
+	HealthRed     = "red"
+	HealthUnknown = "unknown"
+)
+
+// HealthThreshold scores one broker series. The value turns yellow when it
+// reaches Warn and red when it reaches Crit, or when it falls to them if
+// Below is set.
+type HealthThreshold struct {
+	Series string  `json:"series"`
+	Label  string  `json:"label"`
+	Warn   float64 `json:"warn"`
+	Crit   float64 `json:"crit"`
+	Below  bool    `json:"below"`
+}
+
+// Idle percentages are ratios between 0 and 1, latencies are milliseconds
+var HealthThresholds = []HealthThreshold{
+	{"request_handler_idle", "Request handler idle", 0.3, 0.1, true},
+	{"network_processor_idle", "Network processor idle", 0.3, 0.1, true},
+	{"produce_p99", "Produce p99 (ms)", 500, 2000, false},
+	{"fetch_consumer_p99", "Consumer fetch p99 (ms)", 1000, 3000, false},
+	{"under_replicated_partitions", "Under replicated partitions", 1, 10, false},
+	{"offline_partitions", "Offline partitions", 1, 1, false},
+	{"active_controller", "Active controller count", 2, 2, false},
+}
+
+func (t HealthThreshold) Status(v float64) string {
+	if t.Below {
+		switch {
+		case v <= t.Crit:
+			return HealthRed
+		case v <= t.Warn:
+			return HealthYellow
+		}
+		return HealthGreen
+	}
+	switch {
+	case v >= t.Crit:
+		return HealthRed
+	case v >= t.Warn:
+		return HealthYellow
+	}
+	return HealthGreen
+}
+
+type HealthCheck struct {
+	Series string   `json:"series"`
+	Label  string   `json:"label"`
+	Value  *float64 `json:"value"`
+	Warn   float64  `json:"warn"`
+	Crit   float64  `json:"crit"`
+	Status string   `json:"status"`
+}
+
+type Health struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func (h *Health) add(c HealthCheck) {
+	h.Checks = append(h.Checks, c)
+	if worseStatus(c.Status, h.Status) {
+		h.Status = c.Status
+	}
+}
+
+var healthRank = map[string]int{HealthUnknown: 0, HealthGreen: 1, HealthYellow: 2, HealthRed: 3}
+
+func worseStatus(a, b string) bool {
+	return healthRank[a] > healthRank[b]
+}
+
+// BrokerHealth scores the latest sampled values of the broker, series that
+// haven't been sampled yet are unknown and don't affect the score. An
+// offline broker is always red.
+func BrokerHealth(b broker) Health {
+	h := Health{Status: HealthUnknown, Checks: make([]HealthCheck, 0, len(HealthThresholds)+1)}
+	online := HealthCheck{Series: "online", Label: "Online", Status: HealthGreen}
+	if !b.Online() {
+		online.Status = HealthRed
+	}
+	h.add(online)
+	for _, t := range HealthThresholds {
+		c := HealthCheck{Series: t.Series, Label: t.Label, Warn: t.Warn, Crit: t.Crit, Status: HealthUnknown}
+		if b.Series != nil {
+			if v, ok := b.Series.Value(t.Series); ok {
+				c.Value = &v
+				c.Status = t.Status(v)
+			}
+		}
+		h.add(c)
+	}
+	return h
+}
+
+type ClusterHealth struct {
+	Status  string            `json:"status"`
+	Checks  []HealthCheck     `json:"checks"`
+	Brokers map[string]Health `json:"brokers"`
+}
+
+// ClusterHealthReport combines the broker scores with checks that only make
+// sense for the whole cluster, exactly one broker must be the active
+// controller.
+func ClusterHealthReport() ClusterHealth {
+	var (
+		h           = Health{Status: HealthUnknown}
+		brokers     = make(map[string]Health)
+		controllers float64
+		sampled     bool
+	)
+	for _, b := range Brokers() {
+		bh := BrokerHealth(b)
+		brokers[strconv.Itoa(b.Id)] = bh
+		if worseStatus(bh.Status, h.Status) {
+			h.Status = bh.Status
+		}
+		if b.Series == nil {
+			continue
+		}
+		if v, ok := b.Series.Value("active_controller"); ok {
+			controllers += v
+			sampled = true
+		}
+	}
+	c := HealthCheck{
+		Series: "active_controller",
+		Label:  "Active controllers in cluster",
+		Status: HealthUnknown,
+	}
+	if sampled {
+		c.Value = &controllers
+		if controllers == 1 {
+			c.Status = HealthGreen
+		} else {
+			c.Status = HealthRed
+		}
+	}
+	h.add(c)
+	return ClusterHealth{Status: h.Status, Checks: h.Checks, Brokers: brokers}
+}
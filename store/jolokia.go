@@ -0,0 +1,137 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+)
+
+// Reads from the Jolokia agent, https://jolokia.org/reference/html/protocol.html
+// All requests for a broker are sent as one bulk read, beans with wildcards
+// are pattern reads and return one value per matching bean.
+type jolokiaSource struct{}
+
+type jolokiaRequest struct {
+	Type      string `json:"type"`
+	MBean     string `json:"mbean"`
+	Attribute string `json:"attribute"`
+}
+
+type jolokiaResponse struct {
+	Request jolokiaRequest  `json:"request"`
+	Value   json.RawMessage `json:"value"`
+	Status  int             `json:"status"`
+	Error   string          `json:"error"`
+}
+
+func (jolokiaSource) Fetch(ctx context.Context, brokerId int, reqs []MetricRequest) ([][]Metric, error) {
+	url := config.BrokerUrls.JolokiaUrl(brokerId)
+	if url == "" {
+		return nil, fmt.Errorf("Broker %d not available", brokerId)
+	}
+	body := make([]jolokiaRequest, len(reqs))
+	for i, r := range reqs {
+		body[i] = jolokiaRequest{Type: "read", MBean: r.Bean.String(), Attribute: r.Attr}
+	}
+	enc, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(enc))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		log.Warn("jolokia_request", log.MapEntry{"url": url, "status": resp.StatusCode})
+		return nil, fmt.Errorf("URL %s returned %d", url, resp.StatusCode)
+	}
+	var results []jolokiaResponse
+	if err = json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(reqs) {
+		return nil, fmt.Errorf("Jolokia returned %d responses for %d requests", len(results), len(reqs))
+	}
+	var (
+		res      = make([][]Metric, len(results))
+		firstErr error
+	)
+	for i, r := range results {
+		if r.Status != 200 {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Jolokia %s: %s", r.Request.MBean, r.Error)
+			}
+			continue
+		}
+		res[i] = jolokiaMetrics(r)
+	}
+	return res, firstErr
+}
+
+// A plain read returns the attribute value, a pattern read returns
+// {"<bean>": {"<attribute>": value}}
+func jolokiaMetrics(r jolokiaResponse) []Metric {
+	var value float64
+	if err := json.Unmarshal(r.Value, &value); err == nil {
+		return []Metric{jolokiaMetric(r.Request.MBean, r.Request.Attribute, value)}
+	}
+	var beans map[string]map[string]interface{}
+	if err := json.Unmarshal(r.Value, &beans); err != nil {
+		return nil
+	}
+	res := make([]Metric, 0, len(beans))
+	for bean, attrs := range beans {
+		for attr, v := range attrs {
+			if f, ok := v.(float64); ok {
+				res = append(res, jolokiaMetric(bean, attr, f))
+			}
+		}
+	}
+	return res
+}
+
+func jolokiaMetric(bean, attr string, value float64) Metric {
+	m := Metric{Attribute: attr, Value: value}
+	i := strings.Index(bean, ":")
+	if i < 0 {
+		return m
+	}
+	for _, p := range strings.Split(bean[i+1:], ",") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.Trim(kv[1], "\"")
+		switch kv[0] {
+		case "name":
+			m.Name = v
+		case "type":
+			m.Type = v
+		case "topic":
+			m.Topic = v
+		case "partition":
+			m.Partition = v
+		case "listener":
+			m.Listener = v
+		case "networkProcessor":
+			m.NetworkProcessor = v
+		case "request":
+			m.Request = v
+		case "key":
+			m.Key = v
+		}
+	}
+	return m
+}
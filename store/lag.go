@@ -0,0 +1,112 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Lag is sampled every SampleTime, this keeps an hour
+	maxLagPoints = 360
+	// Rates and growth are measured over this window
+	lagWindow = 5 * time.Minute
+)
+
+type lagPoint struct {
+	time      time.Time
+	endOffset int64
+	lag       int64
+}
+
+var (
+	lagLock    sync.RWMutex
+	lagHistory = make(map[string]map[string][]lagPoint)
+)
+
+// recordLag adds the summed lag and log end offset per group and topic,
+// cgs is all groups so the history of groups and topics that are gone is
+// dropped
+func recordLag(cgs ConsumerGroups, now time.Time) {
+	lagLock.Lock()
+	defer lagLock.Unlock()
+	for group := range lagHistory {
+		if _, ok := cgs[group]; !ok {
+			delete(lagHistory, group)
+		}
+	}
+	for group, members := range cgs {
+		sums := make(map[string]lagPoint)
+		for _, m := range members {
+			p := sums[m.Topic]
+			p.time = now
+			p.endOffset += int64(m.LogEndOffset)
+			p.lag += int64(m.Lag())
+			sums[m.Topic] = p
+		}
+		topics, ok := lagHistory[group]
+		if !ok {
+			topics = make(map[string][]lagPoint)
+			lagHistory[group] = topics
+		}
+		for topic := range topics {
+			if _, ok := sums[topic]; !ok {
+				delete(topics, topic)
+			}
+		}
+		for topic, p := range sums {
+			h := append(topics[topic], p)
+			if len(h) > maxLagPoints {
+				h = h[len(h)-maxLagPoints:]
+			}
+			topics[topic] = h
+		}
+	}
+}
+
+// TopicLag is the lag of a group on one topic. MessageRate is how fast
+// the log end offsets grow, SecondsBehind estimates how long the group
+// needs to catch up at that rate and is nil while nothing is produced.
+// Growth is the change of the lag in messages per second.
+type TopicLag struct {
+	Topic         string   `json:"topic"`
+	Lag           int64    `json:"lag"`
+	MessageRate   float64  `json:"message_rate"`
+	SecondsBehind *float64 `json:"seconds_behind"`
+	Growth        float64  `json:"growth"`
+}
+
+func topicLag(topic string, h []lagPoint) TopicLag {
+	last := h[len(h)-1]
+	res := TopicLag{Topic: topic, Lag: last.lag}
+	first := last
+	for i := len(h) - 1; i >= 0 && last.time.Sub(h[i].time) <= lagWindow; i-- {
+		first = h[i]
+	}
+	if dt := last.time.Sub(first.time).Seconds(); dt > 0 {
+		res.MessageRate = float64(last.endOffset-first.endOffset) / dt
+		res.Growth = float64(last.lag-first.lag) / dt
+	}
+	if res.Lag == 0 {
+		zero := float64(0)
+		res.SecondsBehind = &zero
+	} else if res.MessageRate > 0 {
+		behind := float64(res.Lag) / res.MessageRate
+		res.SecondsBehind = &behind
+	}
+	return res
+}
+
+// GroupLag returns the lag per topic of the group, sorted on topic
+func GroupLag(group string) []TopicLag {
+	lagLock.RLock()
+	defer lagLock.RUnlock()
+	res := make([]TopicLag, 0)
+	for topic, h := range lagHistory[group] {
+		if len(h) > 0 {
+			res = append(res, topicLag(topic, h))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Topic < res[j].Topic })
+	return res
+}
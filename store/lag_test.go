@@ -0,0 +1,129 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func lagPoints(start time.Time, step time.Duration, points ...[2]int64) []lagPoint {
+	res := make([]lagPoint, len(points))
+	for i, p := range points {
+		res[i] = lagPoint{start.Add(time.Duration(i) * step), p[0], p[1]}
+	}
+	return res
+}
+
+func TestTopicLag(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		points   []lagPoint
+		lag      int64
+		rate     float64
+		growth   float64
+		behind   *float64
+		noBehind bool
+	}{
+		{name: "one point", points: lagPoints(t0, 0, [2]int64{100, 10}),
+			lag: 10, noBehind: true},
+		{name: "one point without lag", points: lagPoints(t0, 0, [2]int64{100, 0}),
+			behind: floatPtr(0)},
+		{name: "growing lag", points: lagPoints(t0, time.Minute, [2]int64{0, 0}, [2]int64{600, 60}),
+			lag: 60, rate: 10, growth: 1, behind: floatPtr(6)},
+		{name: "catching up", points: lagPoints(t0, time.Minute, [2]int64{0, 120}, [2]int64{600, 60}),
+			lag: 60, rate: 10, growth: -1, behind: floatPtr(6)},
+		{name: "nothing produced", points: lagPoints(t0, time.Minute, [2]int64{600, 50}, [2]int64{600, 50}),
+			lag: 50, noBehind: true},
+		// Only the points within lagWindow of the last one count
+		{name: "window", points: lagPoints(t0, 5*time.Minute, [2]int64{0, 0}, [2]int64{3000, 300}, [2]int64{6000, 600}),
+			lag: 600, rate: 10, growth: 1, behind: floatPtr(60)},
+	}
+	for _, tt := range tests {
+		tl := topicLag("orders", tt.points)
+		if tl.Topic != "orders" || tl.Lag != tt.lag {
+			t.Errorf("%s: expected lag %d, got %d", tt.name, tt.lag, tl.Lag)
+		}
+		if tl.MessageRate != tt.rate {
+			t.Errorf("%s: expected message rate %v, got %v", tt.name, tt.rate, tl.MessageRate)
+		}
+		if tl.Growth != tt.growth {
+			t.Errorf("%s: expected growth %v, got %v", tt.name, tt.growth, tl.Growth)
+		}
+		switch {
+		case tt.noBehind && tl.SecondsBehind != nil:
+			t.Errorf("%s: expected no seconds behind, got %v", tt.name, *tl.SecondsBehind)
+		case tt.behind != nil && (tl.SecondsBehind == nil || *tl.SecondsBehind != *tt.behind):
+			t.Errorf("%s: expected %v seconds behind, got %v", tt.name, *tt.behind, tl.SecondsBehind)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func resetLagHistory(t *testing.T) {
+	lagLock.Lock()
+	lagHistory = make(map[string]map[string][]lagPoint)
+	lagLock.Unlock()
+	t.Cleanup(func() {
+		lagLock.Lock()
+		lagHistory = make(map[string]map[string][]lagPoint)
+		lagLock.Unlock()
+	})
+}
+
+func TestRecordLag(t *testing.T) {
+	resetLagHistory(t)
+	now := time.Unix(1700000000, 0)
+	recordLag(ConsumerGroups{
+		"app": {
+			{Topic: "orders", Partition: 0, CurrentOffset: 10, LogEndOffset: 15},
+			{Topic: "orders", Partition: 1, CurrentOffset: 20, LogEndOffset: 30},
+			{Topic: "payments", Partition: 0, CurrentOffset: 5, LogEndOffset: 5},
+		},
+		"batch": {
+			{Topic: "orders", Partition: 0, CurrentOffset: 0, LogEndOffset: 15},
+		},
+	}, now)
+	lags := GroupLag("app")
+	if len(lags) != 2 || lags[0].Topic != "orders" || lags[1].Topic != "payments" {
+		t.Fatalf("Expected lag of orders and payments, got %+v", lags)
+	}
+	if lags[0].Lag != 15 || lags[1].Lag != 0 {
+		t.Errorf("Expected lag 15 and 0, got %d and %d", lags[0].Lag, lags[1].Lag)
+	}
+
+	// payments is no longer consumed and batch is gone
+	recordLag(ConsumerGroups{
+		"app": {{Topic: "orders", Partition: 0, CurrentOffset: 15, LogEndOffset: 15}},
+	}, now.Add(SampleTime))
+	lagLock.RLock()
+	_, batch := lagHistory["batch"]
+	app := lagHistory["app"]
+	lagLock.RUnlock()
+	if batch {
+		t.Error("Expected history of removed group to be dropped")
+	}
+	if _, ok := app["payments"]; ok || len(app["orders"]) != 2 {
+		t.Errorf("Expected only orders with 2 points, got %v", app)
+	}
+}
+
+func TestRecordLagMaxPoints(t *testing.T) {
+	resetLagHistory(t)
+	now := time.Unix(1700000000, 0)
+	cgs := ConsumerGroups{"app": {{Topic: "orders", CurrentOffset: 0, LogEndOffset: 1}}}
+	for i := 0; i < maxLagPoints+10; i++ {
+		recordLag(cgs, now.Add(time.Duration(i)*SampleTime))
+	}
+	lagLock.RLock()
+	h := lagHistory["app"]["orders"]
+	lagLock.RUnlock()
+	if len(h) != maxLagPoints {
+		t.Fatalf("Expected %d points, got %d", maxLagPoints, len(h))
+	}
+	if last := now.Add(time.Duration(maxLagPoints+9) * SampleTime); !h[len(h)-1].time.Equal(last) {
+		t.Errorf("Expected the newest points to be kept, last is %s", h[len(h)-1].time)
+	}
+}
@@ -0,0 +1,329 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+)
+
+// The Go client has no DescribeLogDirs, so the request is sent with the
+// plain Kafka protocol. Only PLAINTEXT listeners are supported. Version 4
+// (Kafka 3.3+) is used when the broker supports it since it also reports
+// total and usable bytes of the log dir, older brokers get version 1.
+
+const (
+	apiKeyApiVersions          int16 = 18
+	apiKeyDescribeLogDirs      int16 = 35
+	describeLogDirsVersion     int16 = 1
+	describeLogDirsFlexible    int16 = 4
+	describeLogDirsClientId          = "cloudkarafka-manager"
+	describeLogDirsCorrelation int32 = 1
+	errorKafkaStorage          int16 = 56
+)
+
+type LogDirPartition struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Size      int64  `json:"size"`
+	OffsetLag int64  `json:"offset_lag"`
+	IsFuture  bool   `json:"is_future"`
+}
+
+// TotalBytes and UsableBytes are -1 when the broker doesn't report them
+type LogDir struct {
+	Path        string            `json:"path"`
+	Error       string            `json:"error,omitempty"`
+	Offline     bool              `json:"offline"`
+	TotalBytes  int64             `json:"total_bytes"`
+	UsableBytes int64             `json:"usable_bytes"`
+	Partitions  []LogDirPartition `json:"partitions"`
+}
+
+func (d LogDir) Size() int64 {
+	var sum int64
+	for _, p := range d.Partitions {
+		sum += p.Size
+	}
+	return sum
+}
+
+// DescribeLogDirs returns the log dirs of the broker and the size of every
+// partition replica stored in them
+func DescribeLogDirs(ctx context.Context, brokerId int) ([]LogDir, error) {
+	addr := config.BrokerUrls.KafkaUrl(brokerId)
+	if addr == "" {
+		// Brokers only register a host and port for their PLAINTEXT listener
+		if b, ok := Broker(strconv.Itoa(brokerId)); ok && len(b.Endpoints) > 0 {
+			return nil, fmt.Errorf("Broker %d has no PLAINTEXT listener (%s), log dirs can't be described",
+				brokerId, strings.Join(b.Endpoints, ", "))
+		}
+		return nil, fmt.Errorf("Broker %d not available", brokerId)
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(conn)
+	version := describeLogDirsVersion
+	max, err := maxApiVersion(conn, r, apiKeyDescribeLogDirs)
+	if err != nil {
+		return nil, err
+	}
+	if max >= describeLogDirsFlexible {
+		version = describeLogDirsFlexible
+	}
+	if _, err = conn.Write(describeLogDirsRequest(version)); err != nil {
+		return nil, err
+	}
+	body, err := readResponse(r)
+	if err != nil {
+		return nil, err
+	}
+	if version == describeLogDirsFlexible {
+		return parseDescribeLogDirsFlexibleResponse(body)
+	}
+	return parseDescribeLogDirsResponse(body)
+}
+
+func readResponse(r io.Reader) ([]byte, error) {
+	var size int32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func requestHeader(buf *bytes.Buffer, apiKey, version int16) {
+	binary.Write(buf, binary.BigEndian, apiKey)
+	binary.Write(buf, binary.BigEndian, version)
+	binary.Write(buf, binary.BigEndian, describeLogDirsCorrelation)
+	binary.Write(buf, binary.BigEndian, int16(len(describeLogDirsClientId)))
+	buf.WriteString(describeLogDirsClientId)
+}
+
+func sizePrefixed(buf bytes.Buffer) []byte {
+	msg := make([]byte, 4+buf.Len())
+	binary.BigEndian.PutUint32(msg, uint32(buf.Len()))
+	copy(msg[4:], buf.Bytes())
+	return msg
+}
+
+// maxApiVersion asks the broker with ApiVersions v0 which versions of the
+// api it supports, -1 means not at all
+func maxApiVersion(w io.Writer, r io.Reader, apiKey int16) (int16, error) {
+	var buf bytes.Buffer
+	requestHeader(&buf, apiKeyApiVersions, 0)
+	if _, err := w.Write(sizePrefixed(buf)); err != nil {
+		return -1, err
+	}
+	body, err := readResponse(r)
+	if err != nil {
+		return -1, err
+	}
+	p := &protocolReader{r: bytes.NewReader(body)}
+	p.int32() // correlation id
+	if code := p.int16(); code != 0 && p.err == nil {
+		return -1, fmt.Errorf("ApiVersions: error code %d", code)
+	}
+	n := int(p.int32())
+	for i := 0; i < n && p.err == nil; i++ {
+		key, _, max := p.int16(), p.int16(), p.int16()
+		if key == apiKey {
+			return max, p.err
+		}
+	}
+	return -1, p.err
+}
+
+func describeLogDirsRequest(version int16) []byte {
+	var buf bytes.Buffer
+	requestHeader(&buf, apiKeyDescribeLogDirs, version)
+	if version >= describeLogDirsFlexible {
+		// empty header tags, null compact topic array means all topics,
+		// empty body tags
+		buf.Write([]byte{0, 0, 0})
+	} else {
+		// null topic array means all topics
+		binary.Write(&buf, binary.BigEndian, int32(-1))
+	}
+	return sizePrefixed(buf)
+}
+
+type protocolReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (p *protocolReader) read(v interface{}) {
+	if p.err == nil {
+		p.err = binary.Read(p.r, binary.BigEndian, v)
+	}
+}
+
+func (p *protocolReader) int16() int16 {
+	var v int16
+	p.read(&v)
+	return v
+}
+
+func (p *protocolReader) int32() int32 {
+	var v int32
+	p.read(&v)
+	return v
+}
+
+func (p *protocolReader) int64() int64 {
+	var v int64
+	p.read(&v)
+	return v
+}
+
+func (p *protocolReader) bool() bool {
+	var v int8
+	p.read(&v)
+	return v != 0
+}
+
+func (p *protocolReader) string() string {
+	l := p.int16()
+	if p.err != nil || l < 0 {
+		return ""
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(p.r, b); err != nil {
+		p.err = err
+	}
+	return string(b)
+}
+
+func (p *protocolReader) uvarint() uint64 {
+	if p.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(p.r)
+	p.err = err
+	return v
+}
+
+// Compact arrays and strings store length+1, 0 means null
+func (p *protocolReader) compactLen() int {
+	return int(p.uvarint()) - 1
+}
+
+func (p *protocolReader) compactString() string {
+	l := p.compactLen()
+	if p.err != nil || l < 0 {
+		return ""
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(p.r, b); err != nil {
+		p.err = err
+	}
+	return string(b)
+}
+
+func (p *protocolReader) skipTags() {
+	n := int(p.uvarint())
+	for i := 0; i < n && p.err == nil; i++ {
+		p.uvarint() // tag
+		size := int64(p.uvarint())
+		if p.err == nil {
+			_, p.err = p.r.Seek(size, io.SeekCurrent)
+		}
+	}
+}
+
+func logDirError(code int16) (string, bool) {
+	if code == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("error code %d", code), code == errorKafkaStorage
+}
+
+func parseDescribeLogDirsResponse(body []byte) ([]LogDir, error) {
+	p := &protocolReader{r: bytes.NewReader(body)}
+	if p.int32() != describeLogDirsCorrelation && p.err == nil {
+		return nil, errors.New("DescribeLogDirs: unexpected correlation id")
+	}
+	p.int32() // throttle time
+	dirs := make([]LogDir, p.int32())
+	for i := range dirs {
+		dirs[i].Error, dirs[i].Offline = logDirError(p.int16())
+		dirs[i].Path = p.string()
+		dirs[i].TotalBytes, dirs[i].UsableBytes = -1, -1
+		topics := int(p.int32())
+		for t := 0; t < topics && p.err == nil; t++ {
+			name := p.string()
+			partitions := int(p.int32())
+			for n := 0; n < partitions && p.err == nil; n++ {
+				dirs[i].Partitions = append(dirs[i].Partitions, LogDirPartition{
+					Topic:     name,
+					Partition: int(p.int32()),
+					Size:      p.int64(),
+					OffsetLag: p.int64(),
+					IsFuture:  p.bool(),
+				})
+			}
+		}
+	}
+	return dirs, p.err
+}
+
+func parseDescribeLogDirsFlexibleResponse(body []byte) ([]LogDir, error) {
+	p := &protocolReader{r: bytes.NewReader(body)}
+	if p.int32() != describeLogDirsCorrelation && p.err == nil {
+		return nil, errors.New("DescribeLogDirs: unexpected correlation id")
+	}
+	p.skipTags()
+	p.int32() // throttle time
+	if code := p.int16(); code != 0 && p.err == nil {
+		return nil, fmt.Errorf("DescribeLogDirs: error code %d", code)
+	}
+	n := p.compactLen()
+	if p.err != nil || n < 0 {
+		return nil, p.err
+	}
+	dirs := make([]LogDir, n)
+	for i := range dirs {
+		dirs[i].Error, dirs[i].Offline = logDirError(p.int16())
+		dirs[i].Path = p.compactString()
+		topics := p.compactLen()
+		for t := 0; t < topics && p.err == nil; t++ {
+			name := p.compactString()
+			partitions := p.compactLen()
+			for n := 0; n < partitions && p.err == nil; n++ {
+				dirs[i].Partitions = append(dirs[i].Partitions, LogDirPartition{
+					Topic:     name,
+					Partition: int(p.int32()),
+					Size:      p.int64(),
+					OffsetLag: p.int64(),
+					IsFuture:  p.bool(),
+				})
+				p.skipTags()
+			}
+			p.skipTags()
+		}
+		dirs[i].TotalBytes = p.int64()
+		dirs[i].UsableBytes = p.int64()
+		p.skipTags()
+	}
+	return dirs, p.err
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	// DescribeLogDirs v1 response without the size prefix, one healthy log
+	// dir with two partitions and one offline log dir
+	describeLogDirsV1 = []byte{
+		0, 0, 0, 1, // correlation id
+		0, 0, 0, 0, // throttle time
+		0, 0, 0, 2, // log dirs
+		0, 0, // error code
+		0, 11, '/', 'k', 'a', 'f', 'k', 'a', '/', 'd', 'a', 't', 'a',
+		0, 0, 0, 1, // topics
+		0, 6, 'o', 'r', 'd', 'e', 'r', 's',
+		0, 0, 0, 2, // partitions
+		0, 0, 0, 0, // partition
+		0, 0, 0, 0, 0, 0, 4, 0, // size
+		0, 0, 0, 0, 0, 0, 0, 0, // offset lag
+		0,          // is future
+		0, 0, 0, 1, // partition
+		0, 0, 0, 0, 0, 0, 8, 0, // size
+		0, 0, 0, 0, 0, 0, 0, 5, // offset lag
+		1,     // is future
+		0, 56, // error code, KAFKA_STORAGE_ERROR
+		0, 10, '/', 'k', 'a', 'f', 'k', 'a', '/', 'b', 'a', 'd',
+		0, 0, 0, 0, // topics
+	}
+	// The same log dirs as a v4 response, the healthy log dir has an unknown
+	// tagged field that has to be skipped
+	describeLogDirsV4 = []byte{
+		0, 0, 0, 1, // correlation id
+		0,          // header tags
+		0, 0, 0, 0, // throttle time
+		0, 0, // error code
+		3,    // log dirs
+		0, 0, // error code
+		12, '/', 'k', 'a', 'f', 'k', 'a', '/', 'd', 'a', 't', 'a',
+		2, // topics
+		7, 'o', 'r', 'd', 'e', 'r', 's',
+		3,          // partitions
+		0, 0, 0, 0, // partition
+		0, 0, 0, 0, 0, 0, 4, 0, // size
+		0, 0, 0, 0, 0, 0, 0, 0, // offset lag
+		0,          // is future
+		0,          // partition tags
+		0, 0, 0, 1, // partition
+		0, 0, 0, 0, 0, 0, 8, 0, // size
+		0, 0, 0, 0, 0, 0, 0, 5, // offset lag
+		1,                         // is future
+		0,                         // partition tags
+		0,                         // topic tags
+		0, 0, 0, 0, 0, 0, 0x10, 0, // total bytes
+		0, 0, 0, 0, 0, 0, 0x0c, 0, // usable bytes
+		1, 0, 2, 0xab, 0xcd, // log dir tags, tag 0 with 2 bytes
+		0, 56, // error code, KAFKA_STORAGE_ERROR
+		11, '/', 'k', 'a', 'f', 'k', 'a', '/', 'b', 'a', 'd',
+		1,                                              // topics
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // total bytes
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // usable bytes
+		0, // log dir tags
+		0, // response tags
+	}
+)
+
+func expectedLogDirs(total, usable int64) []LogDir {
+	return []LogDir{
+		{
+			Path:        "/kafka/data",
+			TotalBytes:  total,
+			UsableBytes: usable,
+			Partitions: []LogDirPartition{
+				{Topic: "orders", Partition: 0, Size: 1024},
+				{Topic: "orders", Partition: 1, Size: 2048, OffsetLag: 5, IsFuture: true},
+			},
+		},
+		{
+			Path:        "/kafka/bad",
+			Error:       "error code 56",
+			Offline:     true,
+			TotalBytes:  -1,
+			UsableBytes: -1,
+		},
+	}
+}
+
+func TestParseDescribeLogDirsResponse(t *testing.T) {
+	dirs, err := parseDescribeLogDirsResponse(describeLogDirsV1)
+	if err != nil {
+		t.Fatalf("FAILED! %s", err)
+	}
+	if expected := expectedLogDirs(-1, -1); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("FAILED! expected %+v, got %+v", expected, dirs)
+	}
+	if dirs[0].Size() != 3072 {
+		t.Errorf("FAILED! expected size 3072, got %d", dirs[0].Size())
+	}
+}
+
+func TestParseDescribeLogDirsFlexibleResponse(t *testing.T) {
+	dirs, err := parseDescribeLogDirsFlexibleResponse(describeLogDirsV4)
+	if err != nil {
+		t.Fatalf("FAILED! %s", err)
+	}
+	if expected := expectedLogDirs(4096, 3072); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("FAILED! expected %+v, got %+v", expected, dirs)
+	}
+}
+
+func TestParseDescribeLogDirsTruncated(t *testing.T) {
+	if _, err := parseDescribeLogDirsResponse(describeLogDirsV1[:40]); err == nil {
+		t.Error("FAILED! expected error for truncated v1 response")
+	}
+	if _, err := parseDescribeLogDirsFlexibleResponse(describeLogDirsV4[:40]); err == nil {
+		t.Error("FAILED! expected error for truncated v4 response")
+	}
+}
+
+func TestParseDescribeLogDirsCorrelationId(t *testing.T) {
+	body := append([]byte{0, 0, 0, 2}, describeLogDirsV1[4:]...)
+	if _, err := parseDescribeLogDirsResponse(body); err == nil {
+		t.Error("FAILED! expected error for wrong correlation id")
+	}
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+)
+
+// MetricSource reads JMX values from a broker. Fetch gets all requests in
+// one go when the source supports bulk reads, beans may contain wildcards.
+// The result has one slice of metrics per request, in the same order.
+type MetricSource interface {
+	Fetch(ctx context.Context, brokerId int, reqs []MetricRequest) ([][]Metric, error)
+}
+
+var metricSources = map[string]MetricSource{
+	"reporter": reporterSource{},
+	"jolokia":  jolokiaSource{},
+}
+
+func metricSource(brokerId int) (MetricSource, error) {
+	name := config.BrokerMetricSource(brokerId)
+	s, ok := metricSources[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown metric source %s for broker %d", name, brokerId)
+	}
+	return s, nil
+}
+
+// CheckMetricSources validates the configured metric sources
+func CheckMetricSources() error {
+	if _, ok := metricSources[config.MetricSource]; !ok {
+		return fmt.Errorf("Unknown metric source %s, must be reporter or jolokia", config.MetricSource)
+	}
+	for id, name := range config.BrokerMetricSources {
+		if _, ok := metricSources[name]; !ok {
+			return fmt.Errorf("Unknown metric source %s for broker %d, must be reporter or jolokia", name, id)
+		}
+	}
+	return nil
+}
+
+// The Kafka HTTP Reporter plugin, one request per bean
+type reporterSource struct{}
+
+func (reporterSource) Fetch(ctx context.Context, brokerId int, reqs []MetricRequest) ([][]Metric, error) {
+	host := config.BrokerUrls.HttpUrl(brokerId)
+	if host == "" {
+		return nil, fmt.Errorf("Broker %d not available", brokerId)
+	}
+	var (
+		res      = make([][]Metric, len(reqs))
+		firstErr error
+	)
+	for i, r := range reqs {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		url := fmt.Sprintf("%s/jmx?bean=%s&attrs=%s", host, r.Bean, r.Attr)
+		v, err := doRequest(ctx, url)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for j := range v {
+			if v[j].Attribute == "" {
+				v[j].Attribute = r.Attr
+			}
+		}
+		res[i] = v
+	}
+	return res, firstErr
+}
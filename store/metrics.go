@@ -76,21 +76,11 @@ func GetMetrics(ctx context.Context, query MetricRequest) ([]Metric, error) {
 	default:
 		log.Info("GetMetrics nocache", log.MapEntry{"Attr": query.Attr})
 	}
-	host := config.BrokerUrls.HttpUrl(query.BrokerId)
-	if host == "" {
-		return nil, fmt.Errorf("Broker %d not available", query.BrokerId)
-	}
-	url := fmt.Sprintf("%s/jmx?bean=%s&attrs=%s", host, query.Bean, query.Attr)
 	select {
 	case <-ctx.Done():
 		return []Metric{}, ctx.Err()
 	default:
-		v, err := doRequest(ctx, url)
-		if err == nil {
-			for i, _ := range v {
-				v[i].Broker = query.BrokerId
-			}
-		}
+		v, err := GetBrokerMetrics(ctx, query.BrokerId, []MetricRequest{query})
 		switch query.Attr {
 		case "OneMinuteRate":
 			jmxCache1Min.Set(query.String(), v, cache.DefaultExpiration)
@@ -99,6 +89,31 @@ func GetMetrics(ctx context.Context, query MetricRequest) ([]Metric, error) {
 	}
 }
 
+// GetBrokerMetrics reads all requests from one broker, using the metric
+// source configured for it
+func GetBrokerMetrics(ctx context.Context, brokerId int, reqs []MetricRequest) ([]Metric, error) {
+	perRequest, err := fetchBrokerMetrics(ctx, brokerId, reqs)
+	res := make([]Metric, 0, len(perRequest))
+	for _, v := range perRequest {
+		res = append(res, v...)
+	}
+	return res, err
+}
+
+func fetchBrokerMetrics(ctx context.Context, brokerId int, reqs []MetricRequest) ([][]Metric, error) {
+	source, err := metricSource(brokerId)
+	if err != nil {
+		return nil, err
+	}
+	res, err := source.Fetch(ctx, brokerId, reqs)
+	for _, v := range res {
+		for i := range v {
+			v[i].Broker = brokerId
+		}
+	}
+	return res, err
+}
+
 func getSimpleValue(url string) (string, error) {
 	r, err := http.Get(url)
 	if err != nil {
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+)
+
+// State that should survive restarts is kept as JSON files in
+// config.DataDir. Without data dir nothing is written and loading finds
+// nothing.
+
+// LoadState reads name from the data dir into v, a missing file leaves v
+// untouched
+func LoadState(name string, v interface{}) error {
+	if config.DataDir == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(config.DataDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SaveState writes v to name in the data dir, the file is replaced
+// atomically so a crash never leaves a half written file
+func SaveState(name string, v interface{}) error {
+	if config.DataDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(config.DataDir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(config.DataDir, name)
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
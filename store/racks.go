@@ -0,0 +1,171 @@
+package store
+
+import "sort"
+
+// Racks maps the online brokers to their rack, brokers without rack are
+// left out
+func Racks() map[int]string {
+	res := make(map[int]string)
+	for _, b := range Brokers() {
+		if b.Online() && b.Rack != "" {
+			res[b.Id] = b.Rack
+		}
+	}
+	return res
+}
+
+type PartitionRacks struct {
+	Number   int      `json:"number"`
+	Replicas []int    `json:"replicas"`
+	Racks    []string `json:"racks"`
+	// Racks holding more than one replica
+	Shared []string `json:"shared"`
+}
+
+type TopicRackReport struct {
+	Topic      string           `json:"topic"`
+	RackAware  bool             `json:"rack_aware"`
+	Partitions []PartitionRacks `json:"partitions"`
+}
+
+// TopicRacks reports the partitions of the topic whose replicas share a
+// rack while other racks are left unused. Replicas on brokers without rack
+// are reported with an empty rack and never flagged.
+func TopicRacks(t topic, racks map[int]string) TopicRackReport {
+	res := TopicRackReport{Topic: t.Name, RackAware: true, Partitions: make([]PartitionRacks, 0)}
+	distinct := make(map[string]bool)
+	for _, r := range racks {
+		distinct[r] = true
+	}
+	for _, p := range t.Partitions {
+		pr := PartitionRacks{
+			Number:   p.Number,
+			Replicas: p.Replicas,
+			Racks:    make([]string, len(p.Replicas)),
+			Shared:   make([]string, 0),
+		}
+		count := make(map[string]int)
+		for i, id := range p.Replicas {
+			pr.Racks[i] = racks[id]
+			if pr.Racks[i] != "" {
+				count[pr.Racks[i]]++
+			}
+		}
+		if len(count) >= len(distinct) {
+			continue
+		}
+		for r, n := range count {
+			if n > 1 {
+				pr.Shared = append(pr.Shared, r)
+			}
+		}
+		if len(pr.Shared) > 0 {
+			sort.Strings(pr.Shared)
+			res.Partitions = append(res.Partitions, pr)
+			res.RackAware = false
+		}
+	}
+	return res
+}
+
+// RackAssignment spreads the replicas of new partitions over the racks the
+// same way Kafka does: brokers are ordered alternating between racks, the
+// leaders are placed round robin starting at firstPartition and followers
+// go to the next brokers in racks without a replica of the partition. It
+// returns nil when not all online brokers have a rack, the brokers then
+// assign the replicas themselves.
+func RackAssignment(partitions, replicationFactor, firstPartition int) [][]int32 {
+	online := 0
+	for _, b := range Brokers() {
+		if b.Online() {
+			online++
+		}
+	}
+	racks := Racks()
+	if len(racks) == 0 || len(racks) != online || replicationFactor < 1 || replicationFactor > online {
+		return nil
+	}
+	return rackAssignment(racks, partitions, replicationFactor, firstPartition)
+}
+
+// rackAlternated returns the broker ids ordered as the first broker of every
+// rack, then the second broker of every rack and so on
+func rackAlternated(racks map[int]string) []int {
+	perRack := make(map[string][]int)
+	for id, r := range racks {
+		perRack[r] = append(perRack[r], id)
+	}
+	names := make([]string, 0, len(perRack))
+	for r, ids := range perRack {
+		sort.Ints(ids)
+		names = append(names, r)
+	}
+	sort.Strings(names)
+	res := make([]int, 0, len(racks))
+	for i := 0; len(res) < len(racks); i++ {
+		for _, r := range names {
+			if i < len(perRack[r]) {
+				res = append(res, perRack[r][i])
+			}
+		}
+	}
+	return res
+}
+
+func rackAssignment(racks map[int]string, partitions, replicationFactor, firstPartition int) [][]int32 {
+	var (
+		brokers  = rackAlternated(racks)
+		n        = len(brokers)
+		allRacks = make(map[string]bool)
+		res      = make([][]int32, partitions)
+	)
+	for _, r := range racks {
+		allRacks[r] = true
+	}
+	for i := range res {
+		var (
+			p         = firstPartition + i
+			leader    = p % n
+			replicas  = []int32{int32(brokers[leader])}
+			used      = map[int]bool{brokers[leader]: true}
+			usedRacks = map[string]bool{racks[brokers[leader]]: true}
+		)
+		// The other brokers in order, rotated every round of leaders so
+		// followers don't always end up next to the same leader
+		candidates := make([]int, 0, n-1)
+		for k := 0; k < n-1; k++ {
+			candidates = append(candidates, brokers[(leader+1+(p/n+k)%(n-1))%n])
+		}
+		for pass := 0; pass < 2 && len(replicas) < replicationFactor; pass++ {
+			for _, id := range candidates {
+				if len(replicas) == replicationFactor {
+					break
+				}
+				if used[id] {
+					continue
+				}
+				if pass == 0 && usedRacks[racks[id]] && len(usedRacks) < len(allRacks) {
+					continue
+				}
+				replicas = append(replicas, int32(id))
+				used[id] = true
+				usedRacks[racks[id]] = true
+			}
+		}
+		res[i] = replicas
+	}
+	return res
+}
+
+// RackReports returns the topics with partitions whose replicas share a
+// rack, keyed by topic name
+func RackReports() map[string]TopicRackReport {
+	racks := Racks()
+	res := make(map[string]TopicRackReport)
+	for _, t := range Topics() {
+		if r := TopicRacks(t, racks); !r.RackAware {
+			res[t.Name] = r
+		}
+	}
+	return res
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// checkSpread verifies that every partition has replicationFactor distinct
+// replicas spread over as many racks as possible
+func checkSpread(t *testing.T, racks map[int]string, assignment [][]int32, partitions, replicationFactor int) {
+	t.Helper()
+	distinct := make(map[string]bool)
+	for _, r := range racks {
+		distinct[r] = true
+	}
+	expectedRacks := replicationFactor
+	if len(distinct) < expectedRacks {
+		expectedRacks = len(distinct)
+	}
+	if len(assignment) != partitions {
+		t.Fatalf("Expected %d partitions, got %d", partitions, len(assignment))
+	}
+	for p, replicas := range assignment {
+		if len(replicas) != replicationFactor {
+			t.Errorf("Partition %d: expected %d replicas, got %v", p, replicationFactor, replicas)
+		}
+		brokers := make(map[int32]bool)
+		used := make(map[string]bool)
+		for _, id := range replicas {
+			if _, ok := racks[int(id)]; !ok {
+				t.Errorf("Partition %d: unknown broker %d", p, id)
+			}
+			brokers[id] = true
+			used[racks[int(id)]] = true
+		}
+		if len(brokers) != len(replicas) {
+			t.Errorf("Partition %d: duplicate replicas %v", p, replicas)
+		}
+		if len(used) != expectedRacks {
+			t.Errorf("Partition %d: expected replicas in %d racks, got %v", p, expectedRacks, replicas)
+		}
+	}
+}
+
+func TestRackAssignmentEvenSpread(t *testing.T) {
+	racks := map[int]string{1: "a", 2: "a", 3: "b", 4: "b", 5: "c", 6: "c"}
+	assignment := rackAssignment(racks, 12, 3, 0)
+	checkSpread(t, racks, assignment, 12, 3)
+	var (
+		leaders  = make(map[int32]int)
+		replicas = make(map[int32]int)
+	)
+	for _, r := range assignment {
+		leaders[r[0]]++
+		for _, id := range r {
+			replicas[id]++
+		}
+	}
+	for id := range racks {
+		if leaders[int32(id)] != 2 {
+			t.Errorf("Expected broker %d to lead 2 partitions, got %d", id, leaders[int32(id)])
+		}
+		if replicas[int32(id)] != 6 {
+			t.Errorf("Expected broker %d to hold 6 replicas, got %d", id, replicas[int32(id)])
+		}
+	}
+}
+
+func TestRackAssignmentFewerRacksThanReplicas(t *testing.T) {
+	racks := map[int]string{1: "a", 2: "a", 3: "b", 4: "b"}
+	checkSpread(t, racks, rackAssignment(racks, 8, 3, 0), 8, 3)
+}
+
+func TestRackAssignmentFirstPartition(t *testing.T) {
+	racks := map[int]string{1: "a", 2: "b", 3: "c"}
+	all := rackAssignment(racks, 6, 2, 0)
+	added := rackAssignment(racks, 3, 2, 3)
+	checkSpread(t, racks, added, 3, 2)
+	for i, r := range added {
+		if r[0] != all[3+i][0] {
+			t.Errorf("Partition %d: expected leader %d, got %d", 3+i, all[3+i][0], r[0])
+		}
+	}
+}
+
+func TestRackAlternated(t *testing.T) {
+	racks := map[int]string{1: "a", 2: "a", 3: "a", 4: "b", 5: "c"}
+	expected := []int{1, 4, 5, 2, 3}
+	got := rackAlternated(racks)
+	for i := range expected {
+		if i >= len(got) || got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+// seedBrokers adds online brokers with the given racks to the store
+func seedBrokers(t *testing.T, racks map[int]string) {
+	urls := make(config.BrokerURLs)
+	for id, rack := range racks {
+		b := NewBroker()
+		b.Id, b.Rack = id, rack
+		store.UpdateBroker(b)
+		urls[id] = zookeeper.HostPort{Id: id, Host: "localhost", Port: 9092 + id}
+	}
+	prev := config.BrokerUrls
+	config.BrokerUrls = urls
+	t.Cleanup(func() {
+		config.BrokerUrls = prev
+		store.Lock()
+		defer store.Unlock()
+		for id := range racks {
+			delete(store.brokers, strconv.Itoa(id))
+		}
+	})
+}
+
+func TestRackAssignmentBrokersWithoutRack(t *testing.T) {
+	seedBrokers(t, map[int]string{1: "a", 2: "b", 3: ""})
+	if a := RackAssignment(3, 2, 0); a != nil {
+		t.Errorf("Expected no assignment when a broker has no rack, got %v", a)
+	}
+}
+
+func TestRackAssignmentReplicationFactor(t *testing.T) {
+	seedBrokers(t, map[int]string{1: "a", 2: "b", 3: "c"})
+	if a := RackAssignment(3, 4, 0); a != nil {
+		t.Errorf("Expected no assignment when replication factor exceeds the brokers, got %v", a)
+	}
+	if a := RackAssignment(3, 0, 0); a != nil {
+		t.Errorf("Expected no assignment for replication factor 0, got %v", a)
+	}
+	a := RackAssignment(3, 3, 0)
+	checkSpread(t, Racks(), a, 3, 3)
+}
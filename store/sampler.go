@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+)
+
+const maxSampleStats = 60
+
+// SampleStats describes one round of metric fetching, how many of the
+// brokers answered and how long each of them took
+type SampleStats struct {
+	Started   time.Time
+	Duration  time.Duration
+	Brokers   int
+	BrokersOK int
+	Requests  int
+	Metrics   int
+	Latency   map[int]time.Duration
+	Errors    map[int]string
+}
+
+// Coverage is the share of brokers that answered
+func (s SampleStats) Coverage() float64 {
+	if s.Brokers == 0 {
+		return 1
+	}
+	return float64(s.BrokersOK) / float64(s.Brokers)
+}
+
+func (s SampleStats) MarshalJSON() ([]byte, error) {
+	latency := make(map[string]int64)
+	for id, d := range s.Latency {
+		latency[strconv.Itoa(id)] = d.Milliseconds()
+	}
+	errors := make(map[string]string)
+	for id, e := range s.Errors {
+		errors[strconv.Itoa(id)] = e
+	}
+	return json.Marshal(map[string]interface{}{
+		"started":     s.Started.Unix(),
+		"duration_ms": s.Duration.Milliseconds(),
+		"brokers":     s.Brokers,
+		"brokers_ok":  s.BrokersOK,
+		"coverage":    s.Coverage(),
+		"requests":    s.Requests,
+		"metrics":     s.Metrics,
+		"latency_ms":  latency,
+		"errors":      errors,
+	})
+}
+
+var (
+	sampleStatsLock sync.RWMutex
+	sampleStats     = make([]SampleStats, 0, maxSampleStats)
+)
+
+func recordSample(s SampleStats) {
+	sampleStatsLock.Lock()
+	defer sampleStatsLock.Unlock()
+	if len(sampleStats) == maxSampleStats {
+		copy(sampleStats, sampleStats[1:])
+		sampleStats = sampleStats[:maxSampleStats-1]
+	}
+	sampleStats = append(sampleStats, s)
+	if s.BrokersOK < s.Brokers {
+		log.Warn("metric_sample", log.MapEntry{
+			"coverage": fmt.Sprintf("%.2f", s.Coverage()),
+			"duration": s.Duration.String(),
+		})
+	}
+}
+
+// SampleHistory returns the stats of the latest samples, oldest first
+func SampleHistory() []SampleStats {
+	sampleStatsLock.RLock()
+	defer sampleStatsLock.RUnlock()
+	res := make([]SampleStats, len(sampleStats))
+	copy(res, sampleStats)
+	return res
+}
+
+type brokerSample struct {
+	brokerId int
+	metrics  [][]Metric
+	err      error
+	latency  time.Duration
+}
+
+// FetchMetrics groups the catalogue requests per broker and fetches them
+// with at most config.MetricWorkers brokers in flight, each broker gets
+// config.JMXRequestTimeout to answer
+func FetchMetrics(ctx context.Context, samples chan Sample, reqs []catalogueRequest) {
+	var (
+		perBroker  = make(map[int][]catalogueRequest)
+		jobs       = make(chan int)
+		results    = make(chan brokerSample)
+		aggregator = newSampleAggregator()
+		workers    = config.MetricWorkers
+		stats      = SampleStats{
+			Started:  time.Now(),
+			Requests: len(reqs),
+			Latency:  make(map[int]time.Duration),
+			Errors:   make(map[int]string),
+		}
+	)
+	for _, r := range reqs {
+		perBroker[r.BrokerId] = append(perBroker[r.BrokerId], r)
+	}
+	stats.Brokers = len(perBroker)
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(perBroker) {
+		workers = len(perBroker)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range jobs {
+				started := time.Now()
+				mrs := make([]MetricRequest, len(perBroker[id]))
+				for i, r := range perBroker[id] {
+					mrs[i] = r.MetricRequest
+				}
+				bctx, cancel := context.WithTimeout(ctx, config.JMXRequestTimeout)
+				resp, err := fetchBrokerMetrics(bctx, id, mrs)
+				cancel()
+				results <- brokerSample{id, resp, err, time.Since(started)}
+			}
+		}()
+	}
+	go func() {
+		for id := range perBroker {
+			jobs <- id
+		}
+		close(jobs)
+	}()
+	for range perBroker {
+		r := <-results
+		stats.Latency[r.brokerId] = r.latency
+		if r.err != nil {
+			stats.Errors[r.brokerId] = r.err.Error()
+			log.Error("fetch_metrics", log.ErrorEntry{r.err})
+		} else {
+			stats.BrokersOK += 1
+		}
+		for i, metrics := range r.metrics {
+			if i >= len(perBroker[r.brokerId]) {
+				break
+			}
+			for _, e := range perBroker[r.brokerId][i].entries {
+				for _, m := range metrics {
+					aggregator.add(e, m)
+				}
+			}
+		}
+	}
+	for _, s := range aggregator.result() {
+		samples <- s
+		stats.Metrics += 1
+	}
+	stats.Duration = time.Since(stats.Started)
+	recordSample(stats)
+	events.Publish(events.MetricsSampled, "", stats)
+}
+
+func isLeader(topic, partition string, brokerId int) bool {
+	t, ok := store.Topic(topic)
+	if !ok {
+		return false
+	}
+	i, err := strconv.Atoi(partition)
+	if err != nil || i >= len(t.Partitions) {
+		return false
+	}
+	return t.Partitions[i].Leader == brokerId
+}
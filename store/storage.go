@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
 	humanize "github.com/dustin/go-humanize"
 )
 
@@ -71,6 +72,37 @@ func (me storage) UpdateTopic(t topic) {
 	defer me.Unlock()
 	me.topics[string(t.Name)] = t
 }
+
+// RefreshTopicState takes the partition state and config of a refetched
+// topic, keeping the metrics collected for it, and returns the topic as
+// it was before
+func (me *storage) RefreshTopicState(t topic) (topic, bool) {
+	me.Lock()
+	defer me.Unlock()
+	prev, ok := me.topics[t.Name]
+	if !ok {
+		return prev, false
+	}
+	updated := prev
+	updated.Partitions = make([]Partition, len(t.Partitions))
+	for i, p := range t.Partitions {
+		if i < len(prev.Partitions) {
+			p.Metrics = prev.Partitions[i].Metrics
+		}
+		updated.Partitions[i] = p
+	}
+	updated.Config = t.Config
+	me.topics[t.Name] = updated
+	return prev, true
+}
+func (me *storage) SetTopicTags(name string, tags *zookeeper.TopicTags) {
+	me.Lock()
+	defer me.Unlock()
+	if t, ok := me.topics[name]; ok {
+		t.Tags = tags
+		me.topics[name] = t
+	}
+}
 func (me *storage) UpdateTopicMetric(m Metric) {
 	me.Lock()
 	defer me.Unlock()
@@ -104,6 +136,55 @@ func (me *storage) UpdateTopicMetric(m Metric) {
 	me.topics[m.Topic] = t
 }
 
+// UpdateSample stores a value collected through the metric catalogue
+func (me *storage) UpdateSample(s Sample) {
+	me.Lock()
+	defer me.Unlock()
+	switch s.Scope {
+	case ScopeBroker, ScopeListener:
+		b, ok := me.brokers[strconv.Itoa(s.Broker)]
+		if !ok {
+			return
+		}
+		if s.Scope == ScopeListener {
+			b.Listeners.Get(s.Listener).Add(s.Series, s.Aggregation, s.Value)
+		} else {
+			b.Series.Add(s.Series, s.Aggregation, s.Value)
+		}
+	case ScopeTopic:
+		if t, ok := me.topics[s.Topic]; ok && t.Series != nil {
+			t.Series.Add(s.Series, s.Aggregation, s.Value)
+		}
+	case ScopePartition:
+		t, ok := me.topics[s.Topic]
+		if !ok {
+			return
+		}
+		number, err := strconv.Atoi(s.Partition)
+		if err != nil || len(t.Partitions) <= number {
+			return
+		}
+		if t.Partitions[number].Metrics == nil {
+			t.Partitions[number].Metrics = make(map[string]int)
+		}
+		t.Partitions[number].Metrics[s.Series] = int(s.Value)
+	}
+}
+
+func (me *storage) UpdatePartitionState(topic string, number, leader int, isr []int) {
+	me.Lock()
+	t, ok := me.topics[topic]
+	if !ok || len(t.Partitions) <= number {
+		me.Unlock()
+		return
+	}
+	prev := t.Partitions[number].ISR
+	t.Partitions[number].Leader = leader
+	t.Partitions[number].ISR = isr
+	me.Unlock()
+	publishISRChange(topic, number, prev, isr)
+}
+
 func (me storage) BrokerTopicStats(brokerId int) (int, int, string) {
 	me.RLock()
 	defer me.RUnlock()
@@ -127,24 +208,6 @@ func (me storage) BrokerTopicStats(brokerId int) (int, int, string) {
 	return partitionCount, leaderCount, humanize.Bytes(uint64(size))
 }
 
-func (me *storage) UpdateBrokerMetrics(m Metric) {
-	me.Lock()
-	defer me.Unlock()
-	b, ok := me.brokers[strconv.Itoa(m.Broker)]
-	if !ok {
-		return
-	}
-	switch m.Name {
-	case "BytesInPerSec":
-		b.BytesIn.Add(int(m.Value))
-	case "BytesOutPerSec":
-		b.BytesOut.Add(int(m.Value))
-	case "IsrExpandsPerSec":
-		b.ISRShrink.Add(int(m.Value))
-	case "IsrShrinksPerSec":
-		b.ISRExpand.Add(int(m.Value))
-	}
-}
 func (me storage) SumBrokerSeries(metric string) TimeSerie {
 	me.RLock()
 	defer me.RUnlock()
@@ -199,6 +262,11 @@ func (me storage) Consumer(name string) (ConsumerGroup, bool) {
 		ConsumedPartitions: members,
 		Online:             me.consumers.Online(name),
 	}
+	for _, m := range members {
+		if m.LastSeen > cg.LastSeen {
+			cg.LastSeen = m.LastSeen
+		}
+	}
 	return cg, ok
 }
 
@@ -212,6 +280,7 @@ func (me storage) UpdateConsumers(cgs ConsumerGroups) {
 		}
 		me.consumers[name] = cg
 	}
+	recordLag(cgs, time.Now())
 }
 
 func Uptime() string {
@@ -282,6 +351,9 @@ func UpdateTopic(name string) bool {
 	if err != nil {
 		return false
 	}
+	if prev, ok := store.Topic(name); ok {
+		publishTopicChanges(prev, t)
+	}
 	store.UpdateTopic(t)
 	return true
 }
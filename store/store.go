@@ -2,100 +2,91 @@ package store
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudkarafka/cloudkarafka-manager/config"
-	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
 	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
 const (
 	MaxPoints  int           = 500
 	Timeout    time.Duration = 5 * time.Second
 	SampleTime time.Duration = 10 * time.Second
+	// The topic watch only reports topics being created or deleted, leader,
+	// ISR and config changes are found by refetching all topics this often
+	TopicRefreshTime time.Duration = 30 * time.Second
 )
 
-func FetchMetrics(ctx context.Context, metrics chan Metric, reqs []MetricRequest) {
-	ctx, cancel := context.WithTimeout(ctx, Timeout)
-	defer cancel()
-	for _, r := range reqs {
-		select {
-		case <-ctx.Done():
-			log.Error("fetch_metrics", log.ErrorEntry{ctx.Err()})
-			break
-		default:
-			resp, err := GetMetrics(ctx, r)
-			if err != nil {
-				log.Error("fetch_metrics", log.ErrorEntry{err})
-				continue
-			}
-			for _, r := range resp {
-				metrics <- r
-			}
-		}
-	}
-}
-
-func handleBrokerChanges(hps []zookeeper.HostPort) []MetricRequest {
-	reqs := make([]MetricRequest, len(hps)*4)
-	for i, hp := range hps {
-		copy(reqs[i*4:], []MetricRequest{
-			MetricRequest{hp.Id, BeanBrokerBytesInPerSec, "Count"},
-			MetricRequest{hp.Id, BeanBrokerBytesOutPerSec, "Count"},
-			MetricRequest{hp.Id, BeanBrokerIsrExpands, "Count"},
-			MetricRequest{hp.Id, BeanBrokerIsrShrinks, "Count"},
-		})
+func handleBrokerChanges(hps []zookeeper.HostPort) []catalogueRequest {
+	publishBrokerChanges(hps)
+	for _, hp := range hps {
 		broker, _ := fetchBroker(hp.Id)
+		if prev, ok := store.Broker(strconv.Itoa(hp.Id)); ok {
+			publishBrokerRestart(prev, broker)
+		}
 		store.UpdateBroker(broker)
 	}
-	return reqs
+	return catalogueRequests(hps)
 }
 
-func handleTopicChanges(topics []zookeeper.T) []MetricRequest {
-	reqs := make([]MetricRequest, 0, 5*len(topics))
+func handleTopicChanges(topics []zookeeper.T) {
+	publishTopicListChanges(topics)
 	for _, t := range topics {
-		topic, _ := FetchTopic(t.Name)
+		topic, err := FetchTopic(t.Name)
+		if prev, ok := store.Topic(t.Name); ok && err == nil {
+			publishTopicChanges(prev, topic)
+		}
 		store.UpdateTopic(topic)
-		if 200 < len(topic.Partitions) {
-			continue
+	}
+}
+
+// refreshTopics refetches the topics in the store
+func refreshTopics(out chan []topic) {
+	res := make([]topic, 0)
+	for _, t := range store.Topics() {
+		if topic, err := FetchTopic(t.Name); err == nil {
+			res = append(res, topic)
 		}
-		for _, p := range topic.Partitions {
-			if p.Leader != -1 {
-				reqs = append(reqs, []MetricRequest{
-					MetricRequest{p.Leader, BeanTopicLogSize(t.Name), "Value"},
-					MetricRequest{p.Leader, BeanTopicLogEnd(t.Name), "Value"},
-					MetricRequest{p.Leader, BeanTopicLogStart(t.Name), "Value"},
-					MetricRequest{p.Leader, BeanTopicBytesOutPerSec(t.Name), "Count"},
-					MetricRequest{p.Leader, BeanTopicBytesInPerSec(t.Name), "Count"},
-				}...)
-			}
+	}
+	out <- res
+}
+
+func handleTopicRefresh(topics []topic) {
+	for _, t := range topics {
+		if prev, ok := store.RefreshTopicState(t); ok {
+			publishTopicChanges(prev, t)
 		}
 	}
-	return reqs
 }
 
 func Start() {
 	var (
-		brokerRequests []MetricRequest
-		topicRequests  []MetricRequest
-		ctx            context.Context
-		cancel         context.CancelFunc
+		requests []catalogueRequest
+		ctx      context.Context
+		cancel   context.CancelFunc
 
 		topicChanges  = make(chan []zookeeper.T)
+		refreshed     = make(chan []topic)
 		brokerChanges = make(chan []zookeeper.HostPort)
-		bMetrics      = make(chan Metric)
+		samples       = make(chan Sample)
 		tMetrics      = make(chan Metric)
 		cMetrics      = make(chan ConsumerGroups)
 		ticker        = time.NewTicker(SampleTime)
+		diskTicker    = time.NewTicker(DiskSampleTime)
+		topicTicker   = time.NewTicker(TopicRefreshTime)
 	)
 
-	zookeeper.WatchTopics(topicChanges)
-	zookeeper.WatchBrokers(brokerChanges)
+	metadata.WatchTopics(topicChanges)
+	metadata.WatchBrokers(brokerChanges)
 
 	defer ticker.Stop()
-	defer close(bMetrics)
+	defer diskTicker.Stop()
+	defer topicTicker.Stop()
+	defer close(samples)
 	defer close(tMetrics)
 	defer close(topicChanges)
 	defer close(brokerChanges)
@@ -106,15 +97,22 @@ func Start() {
 				cancel()
 			}
 			ctx, cancel = context.WithCancel(context.Background())
-			go FetchMetrics(ctx, bMetrics, brokerRequests)
-			go FetchMetrics(ctx, tMetrics, topicRequests)
+			publishControllerChange()
+			go FetchMetrics(ctx, samples, requests)
 			go FetchConsumerGroups(ctx, cMetrics)
+			go FetchFallbackMetrics(ctx, tMetrics, config.BrokerUrls.IDs())
+		case <-diskTicker.C:
+			go FetchDiskUsage(config.BrokerUrls.IDs())
+		case <-topicTicker.C:
+			go refreshTopics(refreshed)
+		case topics := <-refreshed:
+			handleTopicRefresh(topics)
 		case hps := <-brokerChanges:
-			brokerRequests = handleBrokerChanges(hps)
+			requests = handleBrokerChanges(hps)
 		case topics := <-topicChanges:
-			topicRequests = handleTopicChanges(topics)
-		case metric := <-bMetrics:
-			store.UpdateBrokerMetrics(metric)
+			handleTopicChanges(topics)
+		case s := <-samples:
+			store.UpdateSample(s)
 		case metric := <-tMetrics:
 			store.UpdateTopicMetric(metric)
 		case cgs := <-cMetrics:
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/config"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
+
+// Topic tags are kept in ZooKeeper so all manager instances share them, a
+// watch reloads them when another instance changes them. KRaft clusters
+// have no ZooKeeper and keep them in the data dir instead, they are only
+// shared by instances using the same data dir and read at start.
+const topicTagsFile = "topic_tags.json"
+
+var (
+	tagsLock  sync.RWMutex
+	topicTags = make(map[string]zookeeper.TopicTags)
+)
+
+// LoadTopicTags reads the tags of all topics and starts watching for
+// changes made by other managers
+func LoadTopicTags() error {
+	if err := loadTopicTags(); err != nil {
+		return err
+	}
+	if !config.KRaft() {
+		go zookeeper.WatchTopicTags(func() {
+			if err := loadTopicTags(); err != nil {
+				log.Error("load_topic_tags", log.ErrorEntry{err})
+			}
+		})
+	}
+	return nil
+}
+
+func loadTopicTags() error {
+	var (
+		tags = make(map[string]zookeeper.TopicTags)
+		err  error
+	)
+	if config.KRaft() {
+		err = LoadState(topicTagsFile, &tags)
+	} else {
+		tags, err = zookeeper.AllTopicTags()
+	}
+	if err != nil {
+		return err
+	}
+	tagsLock.Lock()
+	topicTags = tags
+	tagsLock.Unlock()
+	for _, t := range store.Topics() {
+		store.SetTopicTags(t.Name, topicTagsRef(t.Name))
+	}
+	return nil
+}
+
+func TopicTags(name string) (zookeeper.TopicTags, bool) {
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+	t, ok := topicTags[name]
+	return t, ok
+}
+
+func topicTagsRef(name string) *zookeeper.TopicTags {
+	if t, ok := TopicTags(name); ok {
+		return &t
+	}
+	return nil
+}
+
+// SetTopicTags replaces the tags of the topic
+func SetTopicTags(name string, tags zookeeper.TopicTags, user string) (zookeeper.TopicTags, error) {
+	if err := tags.Validate(); err != nil {
+		return tags, err
+	}
+	tags.UpdatedBy, tags.UpdatedAt = user, time.Now()
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	var err error
+	if config.KRaft() {
+		updated := make(map[string]zookeeper.TopicTags, len(topicTags)+1)
+		for k, v := range topicTags {
+			updated[k] = v
+		}
+		updated[name] = tags
+		err = SaveState(topicTagsFile, updated)
+	} else {
+		err = zookeeper.SetTopicTags(name, tags)
+	}
+	if err != nil {
+		return tags, err
+	}
+	topicTags[name] = tags
+	store.SetTopicTags(name, &tags)
+	return tags, nil
+}
+
+// dropTopicTags removes the tags of a deleted topic so a new topic with
+// the same name starts without
+func dropTopicTags(name string) {
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	if _, ok := topicTags[name]; !ok {
+		return
+	}
+	delete(topicTags, name)
+	var err error
+	if config.KRaft() {
+		err = SaveState(topicTagsFile, topicTags)
+	} else {
+		err = zookeeper.DeleteTopicTags(name)
+	}
+	if err != nil {
+		log.Error("drop_topic_tags", log.ErrorEntry{err})
+	}
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+)
+
+const (
+	timelineFile = "timeline.json"
+	// Entries older than this are dropped
+	timelineRetention = 30 * 24 * time.Hour
+	// ISR changes of a topic this close to each other are one burst
+	isrBurstWindow = time.Minute
+	// How often a changed timeline is written to disk
+	timelineSaveInterval = 30 * time.Second
+)
+
+// TimelineEntry is an event in the cluster timeline. ISR changes are
+// collapsed into bursts per topic that last from Time until Until.
+type TimelineEntry struct {
+	events.Event
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// ISRBurst is the data of a collapsed ISR change entry
+type ISRBurst struct {
+	Shrinks    int   `json:"shrinks"`
+	Expands    int   `json:"expands"`
+	Partitions []int `json:"partitions"`
+}
+
+var (
+	timelineLock  sync.RWMutex
+	timeline      = make([]TimelineEntry, 0)
+	timelineDirty bool
+
+	// Alerts and samples have their own history
+	timelineTypes = []string{"broker", "controller", "topic", "partition"}
+)
+
+// StartTimeline loads the persisted timeline, it's saved every
+// timelineSaveInterval rather than on every event. Without a data dir the
+// timeline is only kept in memory and starts empty on every restart.
+func StartTimeline() error {
+	timelineLock.Lock()
+	err := LoadState(timelineFile, &timeline)
+	timelineLock.Unlock()
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range time.Tick(timelineSaveInterval) {
+			SaveTimeline()
+		}
+	}()
+	return nil
+}
+
+// SaveTimeline writes the timeline if it changed since it was last saved
+func SaveTimeline() {
+	timelineLock.Lock()
+	if !timelineDirty {
+		timelineLock.Unlock()
+		return
+	}
+	entries := make([]TimelineEntry, len(timeline))
+	copy(entries, timeline)
+	timelineDirty = false
+	timelineLock.Unlock()
+	if err := SaveState(timelineFile, entries); err != nil {
+		log.Error("save_timeline", log.ErrorEntry{err})
+	}
+}
+
+// publish sends the event to subscribers and records it in the timeline.
+// The timeline doesn't subscribe since subscribers that fall behind, e.g.
+// when a lot of metrics are sampled, lose events.
+func publish(typ, name string, data interface{}) {
+	recordTimeline(events.Publish(typ, name, data))
+}
+
+func recordTimeline(e events.Event) {
+	if !inTimeline(e) {
+		return
+	}
+	timelineLock.Lock()
+	defer timelineLock.Unlock()
+	if e.Type == events.ISRChanged {
+		e = isrBurst(e)
+	}
+	if e.Type != "" {
+		timeline = append(timeline, TimelineEntry{Event: e})
+	}
+	cutoff := e.Time.Add(-timelineRetention)
+	i := sort.Search(len(timeline), func(i int) bool { return timeline[i].Time.After(cutoff) })
+	timeline = timeline[i:]
+	timelineDirty = true
+}
+
+func inTimeline(e events.Event) bool {
+	for _, t := range timelineTypes {
+		if e.Resource() == t {
+			return true
+		}
+	}
+	return false
+}
+
+// isrBurst adds the ISR change to the topic's ongoing burst, or returns
+// the first event of a new burst. An event with empty type is returned
+// when it was merged. Caller must hold timelineLock.
+func isrBurst(e events.Event) events.Event {
+	var (
+		data, _      = e.Data.(map[string]interface{})
+		prev, _      = data["previous"].([]int)
+		isr, _       = data["current"].([]int)
+		partition, _ = data["partition"].(int)
+	)
+	for i := len(timeline) - 1; i >= 0; i-- {
+		entry := &timeline[i]
+		if entry.Type != events.ISRChanged || entry.Name != e.Name {
+			continue
+		}
+		// Bursts loaded from disk aren't continued
+		burst, ok := entry.Data.(ISRBurst)
+		last := entry.Time
+		if entry.Until != nil {
+			last = *entry.Until
+		}
+		if !ok || e.Time.Sub(last) > isrBurstWindow {
+			break
+		}
+		burst.add(partition, len(prev), len(isr))
+		entry.Data = burst
+		until := e.Time
+		entry.Until = &until
+		return events.Event{}
+	}
+	burst := ISRBurst{Partitions: make([]int, 0, 1)}
+	burst.add(partition, len(prev), len(isr))
+	e.Data = burst
+	return e
+}
+
+func (b *ISRBurst) add(partition, prev, isr int) {
+	if isr < prev {
+		b.Shrinks += 1
+	} else {
+		b.Expands += 1
+	}
+	for _, p := range b.Partitions {
+		if p == partition {
+			return
+		}
+	}
+	b.Partitions = append(b.Partitions, partition)
+	sort.Ints(b.Partitions)
+}
+
+// Timeline returns the entries between from and to, optionally only those
+// whose type starts with one of the given prefixes
+func Timeline(from, to time.Time, types []string) []TimelineEntry {
+	timelineLock.RLock()
+	defer timelineLock.RUnlock()
+	res := make([]TimelineEntry, 0)
+	for _, e := range timeline {
+		end := e.Time
+		if e.Until != nil {
+			end = *e.Until
+		}
+		if end.Before(from) || e.Time.After(to) {
+			continue
+		}
+		if len(types) > 0 && !hasPrefix(e.Type, types) {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func hasPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
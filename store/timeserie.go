@@ -1,5 +1,11 @@
 package store
 
+import (
+	"encoding/json"
+	"math"
+	"sync"
+)
+
 type TimeSerie interface {
 	Interval() int
 	All() []int
@@ -86,3 +92,157 @@ func (me *SumTimeSerie) Len() int {
 	}
 	return l
 }
+
+// Stores the values as they are, for gauges. Points are kept as floats
+// since many gauges are ratios between 0 and 1, All rounds them.
+type GaugeTimeSerie struct {
+	Points []float64 `json:"points"`
+}
+
+func NewGaugeTimeSerie(maxPoints int) *GaugeTimeSerie {
+	return &GaugeTimeSerie{Points: make([]float64, maxPoints)}
+}
+
+func (me *GaugeTimeSerie) Add(y int) {
+	me.AddFloat(float64(y))
+}
+func (me *GaugeTimeSerie) AddFloat(y float64) {
+	copy(me.Points, me.Points[1:])
+	me.Points[me.Len()-1] = y
+}
+func (me *GaugeTimeSerie) Interval() int {
+	return 0
+}
+func (me *GaugeTimeSerie) All() []int {
+	res := make([]int, len(me.Points))
+	for i, p := range me.Points {
+		res[i] = int(math.Round(p))
+	}
+	return res
+}
+func (me *GaugeTimeSerie) Floats() []float64 {
+	return me.Points
+}
+func (me *GaugeTimeSerie) Last() int {
+	if len(me.Points) == 0 {
+		return 0
+	}
+	return int(math.Round(me.Points[me.Len()-1]))
+}
+func (me *GaugeTimeSerie) Len() int {
+	return len(me.Points)
+}
+
+type WritableTimeSerie interface {
+	TimeSerie
+	Add(y int)
+}
+
+// Series collected through the metric catalogue for one broker, topic or
+// listener. Values keeps the latest sample unrounded.
+type SeriesSet struct {
+	sync.RWMutex
+	Series map[string]WritableTimeSerie
+	Values map[string]float64
+}
+
+func NewSeriesSet() *SeriesSet {
+	return &SeriesSet{
+		Series: make(map[string]WritableTimeSerie),
+		Values: make(map[string]float64),
+	}
+}
+
+func (me *SeriesSet) Add(series, aggregation string, value float64) {
+	me.Lock()
+	defer me.Unlock()
+	s, ok := me.Series[series]
+	if !ok {
+		if aggregation == AggregationRate {
+			s = NewSimpleTimeSerie(5, MaxPoints)
+		} else {
+			s = NewGaugeTimeSerie(MaxPoints)
+		}
+		me.Series[series] = s
+	}
+	if g, ok := s.(*GaugeTimeSerie); ok {
+		g.AddFloat(value)
+	} else {
+		s.Add(int(value))
+	}
+	me.Values[series] = value
+}
+
+func (me *SeriesSet) Value(series string) (float64, bool) {
+	me.RLock()
+	defer me.RUnlock()
+	v, ok := me.Values[series]
+	return v, ok
+}
+
+// Current is the latest point of the series, for rates that's the change
+// per second rather than the raw counter kept in Values
+func (me *SeriesSet) Current(series string) (float64, bool) {
+	me.RLock()
+	defer me.RUnlock()
+	v, ok := me.Values[series]
+	if !ok {
+		return 0, false
+	}
+	if s, ok := me.Series[series].(*SimpleTimeSerie); ok {
+		return float64(s.Last()), true
+	}
+	return v, true
+}
+
+func (me *SeriesSet) MarshalJSON() ([]byte, error) {
+	me.RLock()
+	defer me.RUnlock()
+	series := make(map[string]interface{})
+	for k, s := range me.Series {
+		if g, ok := s.(*GaugeTimeSerie); ok {
+			series[k] = g.Floats()
+		} else {
+			series[k] = s.All()
+		}
+	}
+	return json.Marshal(map[string]interface{}{
+		"series": series,
+		"values": me.Values,
+	})
+}
+
+// SeriesGroup holds one SeriesSet per name, e.g. per listener
+type SeriesGroup struct {
+	sync.RWMutex
+	sets map[string]*SeriesSet
+}
+
+func NewSeriesGroup() *SeriesGroup {
+	return &SeriesGroup{sets: make(map[string]*SeriesSet)}
+}
+
+func (me *SeriesGroup) Get(name string) *SeriesSet {
+	me.Lock()
+	defer me.Unlock()
+	s, ok := me.sets[name]
+	if !ok {
+		s = NewSeriesSet()
+		me.sets[name] = s
+	}
+	return s
+}
+
+func (me *SeriesGroup) All() map[string]*SeriesSet {
+	me.RLock()
+	defer me.RUnlock()
+	res := make(map[string]*SeriesSet, len(me.sets))
+	for k, s := range me.sets {
+		res[k] = s
+	}
+	return res
+}
+
+func (me *SeriesGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(me.All())
+}
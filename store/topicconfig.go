@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	ConfigOpSet      = "set"
+	ConfigOpDelete   = "delete"
+	ConfigOpAppend   = "append"
+	ConfigOpSubtract = "subtract"
+)
+
+var configOpTypes = map[string]kafka.AlterConfigOpType{
+	ConfigOpSet:      kafka.AlterConfigOpTypeSet,
+	ConfigOpDelete:   kafka.AlterConfigOpTypeDelete,
+	ConfigOpAppend:   kafka.AlterConfigOpTypeAppend,
+	ConfigOpSubtract: kafka.AlterConfigOpTypeSubtract,
+}
+
+// ConfigOp is one incremental change of a topic config. Append and
+// subtract add or remove Value from list configs like
+// cleanup.policy, delete removes the override.
+type ConfigOp struct {
+	Op    string `json:"op"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (o ConfigOp) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, ok := configOpTypes[o.Op]; !ok {
+		return fmt.Errorf("op of %s must be one of set, delete, append or subtract", o.Name)
+	}
+	if o.Op != ConfigOpDelete && o.Value == "" {
+		return fmt.Errorf("value is required to %s %s", o.Op, o.Name)
+	}
+	return nil
+}
+
+// AlterTopicConfig applies the operations to the topic config, keys that
+// aren't mentioned are left as they are
+func AlterTopicConfig(ctx context.Context, name string, ops []ConfigOp, user string) error {
+	return alterTopicConfig(ctx, name, ops, user, 0)
+}
+
+func alterTopicConfig(ctx context.Context, name string, ops []ConfigOp, user string, rollbackOf int) error {
+	changes := make([]kafka.ConfigEntry, 0, len(ops))
+	for _, o := range ops {
+		if err := o.Validate(); err != nil {
+			return err
+		}
+		changes = append(changes, kafka.ConfigEntry{
+			Name:                 o.Name,
+			Value:                o.Value,
+			IncrementalOperation: configOpTypes[o.Op]})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	a, err := adminClient()
+	if err != nil {
+		log.Error("update_topic_config", log.ErrorEntry{err})
+		return err
+	}
+	defer a.Close()
+	results, err := a.IncrementalAlterConfigs(ctx,
+		[]kafka.ConfigResource{{Type: kafka.ResourceTopic, Name: name, Config: changes}},
+		kafka.SetAdminRequestTimeout(30*time.Second))
+	if err != nil {
+		log.Error("update_topic_config", log.ErrorEntry{err})
+		return err
+	}
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			log.Error("update_topic_config", log.ErrorEntry{r.Error})
+			return r.Error
+		}
+	}
+	// Append and subtract depend on the current value so the result is
+	// read back from the cluster
+	entries, err := describeTopicConfig(ctx, a, name)
+	if err != nil {
+		log.Error("update_topic_config", log.ErrorEntry{err})
+		return nil
+	}
+	var prev map[string]string
+	if t, ok := store.Topic(name); ok {
+		prev = configStrings(t.Config.Data)
+	}
+	recordConfigVersion(name, prev, overrides(entries), user, rollbackOf)
+	return nil
+}
+
+const (
+	ConfigSourceTopic   = "topic"
+	ConfigSourceBroker  = "broker"
+	ConfigSourceDefault = "default"
+	ConfigSourceUnknown = "unknown"
+)
+
+// TopicConfigEntry is the effective value of a topic config key and where
+// it comes from, the value of sensitive keys is never shown
+type TopicConfigEntry struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Source    string `json:"source"`
+	ReadOnly  bool   `json:"read_only"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+func configSource(s kafka.ConfigSource) string {
+	switch s {
+	case kafka.ConfigSourceDynamicTopic:
+		return ConfigSourceTopic
+	case kafka.ConfigSourceDynamicBroker, kafka.ConfigSourceDynamicDefaultBroker, kafka.ConfigSourceStaticBroker:
+		return ConfigSourceBroker
+	case kafka.ConfigSourceDefault:
+		return ConfigSourceDefault
+	}
+	return ConfigSourceUnknown
+}
+
+func overrides(entries []TopicConfigEntry) map[string]string {
+	res := make(map[string]string)
+	for _, e := range entries {
+		if e.Source == ConfigSourceTopic {
+			res[e.Name] = e.Value
+		}
+	}
+	return res
+}
+
+// DescribeTopicConfig returns the effective config of the topic sorted on
+// name
+func DescribeTopicConfig(ctx context.Context, name string) ([]TopicConfigEntry, error) {
+	a, err := adminClient()
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	return describeTopicConfig(ctx, a, name)
+}
+
+func describeTopicConfig(ctx context.Context, a *kafka.AdminClient, name string) ([]TopicConfigEntry, error) {
+	results, err := a.DescribeConfigs(ctx,
+		[]kafka.ConfigResource{{Type: kafka.ResourceTopic, Name: name}},
+		kafka.SetAdminRequestTimeout(30*time.Second))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]TopicConfigEntry, 0)
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			return nil, r.Error
+		}
+		for _, e := range r.Config {
+			entry := TopicConfigEntry{
+				Name:      e.Name,
+				Value:     e.Value,
+				Source:    configSource(e.Source),
+				ReadOnly:  e.IsReadOnly,
+				Sensitive: e.IsSensitive,
+			}
+			if e.IsSensitive {
+				entry.Value = ""
+			}
+			res = append(res, entry)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
 	"time"
 
+	"github.com/cloudkarafka/cloudkarafka-manager/events"
 	"github.com/cloudkarafka/cloudkarafka-manager/log"
+	"github.com/cloudkarafka/cloudkarafka-manager/metadata"
 	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	humanize "github.com/dustin/go-humanize"
 )
 
@@ -57,13 +58,15 @@ func (me partitions) Size() int {
 }
 
 type topic struct {
-	Name       string           `json:"name"`
-	Partitions partitions       `json:"partitions"`
-	Config     TopicConfig      `json:"config"`
-	Deleted    bool             `json:"deleted"`
-	Metrics    map[string]int   `json:"metrics"`
-	BytesIn    *SimpleTimeSerie `json:"bytes_in"`
-	BytesOut   *SimpleTimeSerie `json:"bytes_out"`
+	Name       string               `json:"name"`
+	Partitions partitions           `json:"partitions"`
+	Config     TopicConfig          `json:"config"`
+	Deleted    bool                 `json:"deleted"`
+	Metrics    map[string]int       `json:"metrics"`
+	BytesIn    *SimpleTimeSerie     `json:"bytes_in"`
+	BytesOut   *SimpleTimeSerie     `json:"bytes_out"`
+	Series     *SeriesSet           `json:"series"`
+	Tags       *zookeeper.TopicTags `json:"tags"`
 }
 
 func (t topic) Size() int {
@@ -73,6 +76,18 @@ func (t topic) Size() int {
 	}
 	return sum
 }
+
+// ReplicationFactor is the largest number of replicas of a partition
+func (t topic) ReplicationFactor() int {
+	rf := 0
+	for _, p := range t.Partitions {
+		if len(p.Replicas) > rf {
+			rf = len(p.Replicas)
+		}
+	}
+	return rf
+}
+
 func (t topic) Messages() int {
 	sum := 0
 	for _, p := range t.Partitions {
@@ -96,9 +111,15 @@ func (t topic) MarshalJSON() ([]byte, error) {
 	if len(t.Metrics) > 0 {
 		res["metrics"] = t.Metrics
 	}
+	if t.Series != nil {
+		res["series"] = t.Series
+	}
 	if len(t.Config.Data) > 0 {
 		res["config"] = t.Config
 	}
+	if t.Tags != nil {
+		res["tags"] = t.Tags
+	}
 	if v := t.Size(); v != 0 {
 		res["size"] = humanize.Bytes(uint64(v))
 	}
@@ -109,10 +130,10 @@ func (t topic) MarshalJSON() ([]byte, error) {
 }
 
 func FetchTopic(topicName string) (topic, error) {
-	tp, err := zookeeper.Topic(topicName)
+	tp, err := metadata.FetchTopic(topicName)
 	if err != nil {
-		if err == zookeeper.PathDoesNotExistsErr {
-			fmt.Fprintf(os.Stderr, "[INFO] FetchTopic: topic %s does not exists in zookeeper", topicName)
+		if err == metadata.TopicDoesNotExistErr {
+			fmt.Fprintf(os.Stderr, "[INFO] FetchTopic: topic %s does not exists", topicName)
 		} else {
 			fmt.Fprintf(os.Stderr, "[INFO] FetchTopic: %s", err)
 		}
@@ -124,21 +145,22 @@ func FetchTopic(topicName string) (topic, error) {
 		Metrics:    make(map[string]int),
 		BytesIn:    NewSimpleTimeSerie(5, MaxPoints),
 		BytesOut:   NewSimpleTimeSerie(5, MaxPoints),
+		Series:     NewSeriesSet(),
 		Config:     TopicConfig{Data: tp.Config},
+		Tags:       topicTagsRef(topicName),
 	}
-	for p, replicas := range tp.Partitions {
-		var par Partition
-		partitionPath := fmt.Sprintf("/brokers/topics/%s/partitions/%s/state", topicName, p)
-	getPartitions:
-		if err := zookeeper.Get(partitionPath, &par); err == nil {
-			i, _ := strconv.Atoi(p)
-			par.Replicas = replicas
-			par.Metrics = make(map[string]int)
-			par.Number = i
-			t.Partitions[i] = par
-		} else {
-			// If the partition for some reason does not alreaady exist retry until it does, this should not happen many times
-			goto getPartitions
+	t.Series.Series["bytes_in"] = t.BytesIn
+	t.Series.Series["bytes_out"] = t.BytesOut
+	for i, p := range tp.Partitions {
+		t.Partitions[i] = Partition{
+			Number:          p.Number,
+			Leader:          p.Leader,
+			Replicas:        p.Replicas,
+			ISR:             p.ISR,
+			LeaderEpoch:     p.LeaderEpoch,
+			Version:         p.Version,
+			ControllerEpoch: p.ControllerEpoch,
+			Metrics:         make(map[string]int),
 		}
 	}
 	return t, nil
@@ -150,13 +172,18 @@ func CreateTopic(ctx context.Context, name string, partitions, replicationFactor
 		log.Error("create_topic", log.ErrorEntry{err})
 		return err
 	}
+	spec := kafka.TopicSpecification{
+		Topic:             name,
+		NumPartitions:     partitions,
+		ReplicationFactor: replicationFactor,
+		Config:            topicConfig}
+	if assignment := RackAssignment(partitions, replicationFactor, 0); assignment != nil {
+		spec.ReplicationFactor = 0
+		spec.ReplicaAssignment = assignment
+	}
 	results, err := a.CreateTopics(
 		ctx,
-		[]kafka.TopicSpecification{{
-			Topic:             name,
-			NumPartitions:     partitions,
-			ReplicationFactor: replicationFactor,
-			Config:            topicConfig}},
+		[]kafka.TopicSpecification{spec},
 		kafka.SetAdminOperationTimeout(15*time.Second))
 	if err != nil {
 		log.Error("create_topic", log.ErrorEntry{err})
@@ -171,38 +198,21 @@ func CreateTopic(ctx context.Context, name string, partitions, replicationFactor
 	return nil
 }
 
-func UpdateTopicConfig(ctx context.Context, name string, topicConfig map[string]interface{}) error {
-	changes := make([]kafka.ConfigEntry, 0)
+// UpdateTopicConfig sets the given keys on the topic, a nil value removes
+// the override so the broker default applies. Other keys are untouched.
+func UpdateTopicConfig(ctx context.Context, name string, topicConfig map[string]interface{}, user string) error {
+	ops := make([]ConfigOp, 0, len(topicConfig))
 	for k, v := range topicConfig {
-		changes = append(changes, kafka.ConfigEntry{
-			Name:      k,
-			Value:     v.(string),
-			Operation: kafka.AlterOperationSet})
-	}
-	a, err := adminClient()
-	if err != nil {
-		log.Error("update_topic_config", log.ErrorEntry{err})
-		return err
-	}
-	configResource := kafka.ConfigResource{
-		Type:   kafka.ResourceTopic,
-		Name:   name,
-		Config: changes,
-	}
-	results, err := a.AlterConfigs(ctx,
-		[]kafka.ConfigResource{configResource},
-		kafka.SetAdminRequestTimeout(30*time.Second))
-	if err != nil {
-		log.Error("update_topic_config", log.ErrorEntry{err})
-		return err
-	}
-	for _, r := range results {
-		if r.Error.Code() != kafka.ErrNoError {
-			log.Error("update_topic_config", log.ErrorEntry{r.Error})
-			return r.Error
+		switch v := v.(type) {
+		case nil:
+			ops = append(ops, ConfigOp{Op: ConfigOpDelete, Name: k})
+		case string:
+			ops = append(ops, ConfigOp{Op: ConfigOpSet, Name: k, Value: v})
+		default:
+			return fmt.Errorf("value of %s must be a string or null", k)
 		}
 	}
-	return nil
+	return AlterTopicConfig(ctx, name, ops, user)
 }
 
 func AddParitions(ctx context.Context, name string, increaseTo int) error {
@@ -214,6 +224,9 @@ func AddParitions(ctx context.Context, name string, increaseTo int) error {
 	spec := kafka.PartitionsSpecification{
 		Topic:      name,
 		IncreaseTo: increaseTo}
+	if t, ok := store.Topic(name); ok && increaseTo > len(t.Partitions) {
+		spec.ReplicaAssignment = RackAssignment(increaseTo-len(t.Partitions), t.ReplicationFactor(), len(t.Partitions))
+	}
 	results, err := a.CreatePartitions(ctx,
 		[]kafka.PartitionsSpecification{spec},
 		kafka.SetAdminRequestTimeout(15*time.Second))
@@ -248,5 +261,8 @@ func DeleteTopic(name string) error {
 		}
 	}
 	store.DeleteTopic(name)
+	dropConfigHistory(name)
+	dropTopicTags(name)
+	publish(events.TopicDeleted, name, nil)
 	return nil
 }
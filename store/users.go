@@ -1,6 +1,9 @@
 package store
 
-import "github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+import (
+	"github.com/cloudkarafka/cloudkarafka-manager/admin"
+	"github.com/cloudkarafka/cloudkarafka-manager/zookeeper"
+)
 
 type KafkaUser struct {
 	Name string
@@ -10,7 +13,7 @@ type KafkaUser struct {
 var noKafkaUsers = []KafkaUser{}
 
 func getSaslUsers(p zookeeper.Permissions) ([]KafkaUser, error) {
-	users, err := zookeeper.Users("", p)
+	users, err := admin.Users("", p)
 	if err != nil {
 		return noKafkaUsers, err
 	}
@@ -17,6 +17,16 @@ const (
 	PrefixedPattern AclPatternType = 1
 )
 
+func (me AclPatternType) String() string {
+	switch me {
+	case LiteralPattern:
+		return "LITERAL"
+	case PrefixedPattern:
+		return "PREFIXED"
+	}
+	return ""
+}
+
 func AclPatternTypeFromString(v string) (AclPatternType, error) {
 	switch strings.ToLower(v) {
 	case "prefixed":
@@ -30,9 +40,11 @@ func AclPatternTypeFromString(v string) (AclPatternType, error) {
 type AclResourceType int
 
 const (
-	ClusterResource AclResourceType = 0
-	TopicResource   AclResourceType = 1
-	GroupResource   AclResourceType = 2
+	ClusterResource         AclResourceType = 0
+	TopicResource           AclResourceType = 1
+	GroupResource           AclResourceType = 2
+	TransactionalIdResource AclResourceType = 3
+	DelegationTokenResource AclResourceType = 4
 )
 
 func (me AclResourceType) String() string {
@@ -43,6 +55,10 @@ func (me AclResourceType) String() string {
 		return "Topic"
 	case GroupResource:
 		return "Group"
+	case TransactionalIdResource:
+		return "TransactionalId"
+	case DelegationTokenResource:
+		return "DelegationToken"
 	}
 	return ""
 }
@@ -54,10 +70,45 @@ func AclResourceFromString(v string) (AclResourceType, error) {
 		return TopicResource, nil
 	case "cluster":
 		return ClusterResource, nil
+	case "transactionalid", "transactional_id":
+		return TransactionalIdResource, nil
+	case "delegationtoken", "delegation_token":
+		return DelegationTokenResource, nil
 	}
 	return -1, errors.New("Unknown resource type")
 }
 
+// Kafka only supports prefixed ACLs for topics, groups and transactional ids
+func (me AclResourceType) SupportsPrefixed() bool {
+	switch me {
+	case TopicResource, GroupResource, TransactionalIdResource:
+		return true
+	}
+	return false
+}
+
+var aclOperations = []string{
+	"All", "Read", "Write", "Create", "Delete", "Alter", "Describe", "ClusterAction",
+	"DescribeConfigs", "AlterConfigs", "IdempotentWrite", "CreateTokens", "DescribeTokens",
+}
+
+// AclOperationFromString returns the canonical Kafka name of an ACL operation,
+// accepting the forms used in ZooKeeper (AlterConfigs), by the UI (alter_configs)
+// and by this API (ALTER_CONFIGS).
+func AclOperationFromString(v string) (string, error) {
+	n := normalizeOperation(v)
+	for _, op := range aclOperations {
+		if normalizeOperation(op) == n {
+			return op, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown operation %s", v)
+}
+
+func normalizeOperation(v string) string {
+	return strings.ToLower(strings.Replace(v, "_", "", -1))
+}
+
 type AclRequest struct {
 	PatternType    AclPatternType
 	ResourceType   AclResourceType
@@ -78,10 +129,10 @@ func (me AclRequest) Equal(acl map[string]string) bool {
 	if me.Principal != acl["principal"] {
 		return false
 	}
-	if me.Permission != acl["operation"] {
+	if !strings.EqualFold(me.Permission, acl["operation"]) {
 		return false
 	}
-	if me.PermissionType != acl["permissionType"] {
+	if !strings.EqualFold(me.PermissionType, acl["permissionType"]) {
 		return false
 	}
 	return true
@@ -98,78 +149,182 @@ func (me AclRequest) Data() map[string]string {
 		"operation":      strings.ToUpper(me.Permission)}
 }
 
+// Uses the same keys as the ACL API accepts when creating a rule
+func (me AclRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"resource_type":   me.ResourceType.String(),
+		"pattern_type":    me.PatternType.String(),
+		"name":            me.Name,
+		"principal":       me.Principal,
+		"permission":      me.Permission,
+		"permission_type": me.PermissionType,
+		"host":            me.Data()["host"],
+	})
+}
+
+// Path and payload for the sequence node that makes the brokers reload the
+// ACLs of this resource
+func (me AclRequest) changeNotification() (string, interface{}) {
+	if me.PatternType == PrefixedPattern {
+		return "/kafka-acl-extended-changes/acl_changes_", map[string]interface{}{
+			"version":      1,
+			"resourceType": me.ResourceType.String(),
+			"name":         me.Name,
+			"patternType":  "PREFIXED",
+		}
+	}
+	return "/kafka-acl-changes/acl_changes_", fmt.Sprintf("%s:%s", me.ResourceType, me.Name)
+}
+
+type aclNode struct {
+	Version int                 `json:"version"`
+	Acls    []map[string]string `json:"acls"`
+}
+
+type aclChanges struct {
+	req    AclRequest
+	add    []AclRequest
+	remove []AclRequest
+}
+
 func CreateAcl(req AclRequest) error {
-	if !Exists(req.Path()) {
-		_, err := conn.Create(req.Path(), []byte("{\"version\": 1, \"acls\": []}"), 0, zk.WorldACL(zk.PermAll))
-		if err != nil {
-			return err
+	return ApplyAcls([]AclRequest{req}, nil)
+}
+
+func DeleteAcl(req AclRequest) error {
+	return ApplyAcls(nil, []AclRequest{req})
+}
+
+// ApplyAcls adds and removes a batch of ACLs. The requests are grouped per
+// resource path so each znode is written, and each change notification
+// created, only once. All nodes are read first and then written in one
+// transaction, so either every change is applied or none, and a node
+// changed by someone else in between fails the whole batch.
+func ApplyAcls(add, remove []AclRequest) error {
+	var (
+		paths   = make([]string, 0)
+		changes = make(map[string]*aclChanges)
+	)
+	group := func(req AclRequest) *aclChanges {
+		c, ok := changes[req.Path()]
+		if !ok {
+			c = &aclChanges{req: req}
+			changes[req.Path()] = c
+			paths = append(paths, req.Path())
 		}
+		return c
 	}
-	node, _, err := conn.Get(req.Path())
-	if err != nil {
-		return err
+	for _, req := range add {
+		c := group(req)
+		c.add = append(c.add, req)
 	}
-	var a struct {
-		Version int                 `json:"version"`
-		Acls    []map[string]string `json:"acls"`
+	for _, req := range remove {
+		c := group(req)
+		c.remove = append(c.remove, req)
 	}
-	err = json.Unmarshal(node, &a)
-	if err != nil {
-		return err
+	var (
+		ops     = make([]interface{}, 0, 2*len(paths))
+		opPaths = make([]string, 0, 2*len(paths))
+	)
+	for _, path := range paths {
+		o, err := aclOps(path, changes[path])
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		for range o {
+			opPaths = append(opPaths, path)
+		}
+		ops = append(ops, o...)
 	}
-	for _, acl := range a.Acls {
-		if req.Equal(acl) {
-			fmt.Println("Already exists!!!")
-			return nil
+	if len(ops) == 0 {
+		return nil
+	}
+	res, err := conn.Multi(ops...)
+	// The failed operation has the error, the ones before it have none
+	for i, r := range res {
+		if r.Error != nil && i < len(opPaths) {
+			return fmt.Errorf("%s: %s, no ACLs were changed", opPaths[i], r.Error)
 		}
 	}
-	return writeAcl(req.Path(), append(a.Acls, req.Data()))
+	return err
 }
 
-func DeleteAcl(req AclRequest) error {
-	node, _, err := conn.Get(req.Path())
-	if err != nil {
-		return err
-	}
-	var a struct {
-		version int
-		acls    []map[string]string
+// aclOps returns the operations that write the changed ACLs of the
+// resource and notify the brokers, none if nothing changed
+func aclOps(path string, c *aclChanges) ([]interface{}, error) {
+	var (
+		a      aclNode
+		exists bool
+		stat   *zk.Stat
+	)
+	node, stat, err := conn.Get(path)
+	if err == nil {
+		exists = true
+		if err = json.Unmarshal(node, &a); err != nil {
+			return nil, err
+		}
+	} else if err != zk.ErrNoNode {
+		return nil, err
 	}
-	err = json.Unmarshal(node, &a)
-	if err != nil {
-		return err
+	var (
+		acls    = make([]map[string]string, 0, len(a.Acls)+len(c.add))
+		changed = false
+	)
+	for _, acl := range a.Acls {
+		keep := true
+		for _, req := range c.remove {
+			if req.Equal(acl) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			acls = append(acls, acl)
+		} else {
+			changed = true
+		}
 	}
-	n := make([]map[string]string, 0)
-	for _, acl := range a.acls {
-		if !req.Equal(acl) {
-			n = append(n, acl)
+	for _, req := range c.add {
+		exists := false
+		for _, acl := range acls {
+			if req.Equal(acl) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			acls = append(acls, req.Data())
+			changed = true
 		}
 	}
-	return writeAcl(req.Path(), n)
-}
-
-func writeAcl(path string, acls []map[string]string) error {
+	if !changed {
+		return nil, nil
+	}
 	data, err := json.Marshal(map[string]interface{}{
 		"version": 1,
 		"acls":    acls,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ok, s, _ := conn.Exists(path)
-	if ok && acls == nil {
-		err = conn.Delete(path, s.Version)
-	} else if ok {
-		_, err = conn.Set(path, data, s.Version)
+	var write interface{}
+	if exists && len(acls) == 0 {
+		write = &zk.DeleteRequest{Path: path, Version: stat.Version}
+	} else if exists {
+		write = &zk.SetDataRequest{Path: path, Data: data, Version: stat.Version}
 	} else {
-		_, err = conn.Create(path, data, 0, zk.WorldACL(zk.PermAll))
-	}
-	if err != nil {
-		return err
-	}
-	parts := strings.Split(path, "/")
-	c := fmt.Sprintf("%s:%s", parts[len(parts)-2], parts[len(parts)-1])
-	return createSeq("/kafka-acl-changes/acl_changes_", c)
+		write = &zk.CreateRequest{Path: path, Data: data, Acl: zk.WorldACL(zk.PermAll)}
+	}
+	notifyPath, notification := c.req.changeNotification()
+	if data, err = encode(notification); err != nil {
+		return nil, err
+	}
+	return []interface{}{write, &zk.CreateRequest{
+		Path:  notifyPath,
+		Data:  data,
+		Acl:   zk.WorldACL(zk.PermAll),
+		Flags: zk.FlagSequence,
+	}}, nil
 }
 
 func parseAclNode(basepath, child, resourceType, pattern string) (ACLRule, error) {
@@ -245,23 +400,16 @@ func childAcls(resourceType string, permFn PermissionFunc) ([]ACLRule, error) {
 
 func Acls(p Permissions) (ACLRules, error) {
 	var a []ACLRule
-	t, err := TopicAcls(p)
-	if err != nil {
-		return a, err
-	}
-	g, err := GroupAcls(p)
-	if err != nil {
-		return a, err
-	}
-	c, err := ClusterAcls(p)
-	if err != nil {
-		return a, err
+	for _, fn := range []func(Permissions) (ACLRules, error){
+		TopicAcls, GroupAcls, ClusterAcls, TransactionalIdAcls, DelegationTokenAcls,
+	} {
+		rules, err := fn(p)
+		if err != nil {
+			return a, err
+		}
+		a = append(a, rules...)
 	}
-	a = make([]ACLRule, len(t)+len(g)+len(c))
-	copy(a[0:], t)
-	copy(a[len(t):], g)
-	copy(a[len(t)+len(g):], c)
-	return a, err
+	return a, nil
 }
 
 func TopicAcls(p Permissions) (ACLRules, error) {
@@ -276,30 +424,38 @@ func ClusterAcls(p Permissions) (ACLRules, error) {
 	return childAcls("Cluster", p.ReadCluster)
 }
 
+func TransactionalIdAcls(p Permissions) (ACLRules, error) {
+	return childAcls("TransactionalId", p.ReadTransactionalId)
+}
+
+func DelegationTokenAcls(p Permissions) (ACLRules, error) {
+	return childAcls("DelegationToken", p.ReadDelegationToken)
+}
+
 func Acl(p Permissions, resourceType, name string) (ACLRule, error) {
 	var (
-		acls []ACLRule
+		acls ACLRules
 		ar   ACLRule
-		err  error
 	)
-	switch resourceType {
-	case "topic":
+	t, err := AclResourceFromString(resourceType)
+	if err != nil {
+		return ar, fmt.Errorf("Resource type must be one of; Topic, Group, Cluster, TransactionalId or DelegationToken, got %s", resourceType)
+	}
+	switch t {
+	case TopicResource:
 		acls, err = TopicAcls(p)
-	case "group":
+	case GroupResource:
 		acls, err = GroupAcls(p)
-	case "cluster":
+	case ClusterResource:
 		acls, err = ClusterAcls(p)
-	default:
-		err = fmt.Errorf("Resource type must be one of; Topic, Group or Cluster, got %s", resourceType)
+	case TransactionalIdResource:
+		acls, err = TransactionalIdAcls(p)
+	case DelegationTokenResource:
+		acls, err = DelegationTokenAcls(p)
 	}
 	if err != nil {
 		return ar, err
 	}
-	for _, r := range acls {
-		if r.Resource.Name == name {
-			ar = r
-			break
-		}
-	}
+	ar, _ = acls.Find(name)
 	return ar, err
 }
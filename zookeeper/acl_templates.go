@@ -0,0 +1,120 @@
+package zookeeper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Templates for the ACLs common client roles need, based on
+// https://docs.confluent.io/current/kafka/authorization.html#using-acls
+
+type AclTemplateParams struct {
+	Principal       string
+	Host            string
+	Topic           string
+	TopicPattern    AclPatternType
+	Group           string
+	TransactionalId string
+}
+
+type AclTemplate struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    []string `json:"required"`
+	build       func(AclTemplateParams) []AclRequest
+}
+
+var AclTemplates = []AclTemplate{
+	{
+		Name:        "producer",
+		Description: "Write to a topic, with idempotence enabled",
+		Required:    []string{"principal", "topic"},
+		build: func(p AclTemplateParams) []AclRequest {
+			return append(
+				p.topic("WRITE", "DESCRIBE"),
+				p.cluster("IDEMPOTENT_WRITE")...)
+		},
+	},
+	{
+		Name:        "consumer",
+		Description: "Read from a topic as a member of a consumer group",
+		Required:    []string{"principal", "topic", "group"},
+		build: func(p AclTemplateParams) []AclRequest {
+			return append(
+				p.topic("READ", "DESCRIBE"),
+				p.acl(GroupResource, LiteralPattern, p.Group, "READ")...)
+		},
+	},
+	{
+		Name:        "transactional-producer",
+		Description: "Write to a topic using transactions",
+		Required:    []string{"principal", "topic", "transactional_id"},
+		build: func(p AclTemplateParams) []AclRequest {
+			res := p.topic("WRITE", "DESCRIBE")
+			res = append(res, p.cluster("IDEMPOTENT_WRITE")...)
+			return append(res,
+				p.acl(TransactionalIdResource, LiteralPattern, p.TransactionalId, "WRITE", "DESCRIBE")...)
+		},
+	},
+	{
+		Name:        "streams-app",
+		Description: "Kafka Streams application, group is the application.id and is used as prefix for internal topics",
+		Required:    []string{"principal", "topic", "group"},
+		build: func(p AclTemplateParams) []AclRequest {
+			res := p.topic("READ", "WRITE", "DESCRIBE")
+			res = append(res, p.acl(TopicResource, PrefixedPattern, p.Group, "ALL")...)
+			res = append(res, p.acl(GroupResource, LiteralPattern, p.Group, "READ")...)
+			res = append(res, p.acl(TransactionalIdResource, PrefixedPattern, p.Group, "WRITE", "DESCRIBE")...)
+			return append(res, p.cluster("IDEMPOTENT_WRITE")...)
+		},
+	},
+}
+
+func AclTemplateByName(name string) (AclTemplate, bool) {
+	for _, t := range AclTemplates {
+		if t.Name == strings.ToLower(name) {
+			return t, true
+		}
+	}
+	return AclTemplate{}, false
+}
+
+// Expand returns the ACLs needed for the role, without applying them
+func (t AclTemplate) Expand(p AclTemplateParams) ([]AclRequest, error) {
+	values := map[string]string{
+		"principal":        p.Principal,
+		"topic":            p.Topic,
+		"group":            p.Group,
+		"transactional_id": p.TransactionalId,
+	}
+	for _, k := range t.Required {
+		if values[k] == "" {
+			return nil, fmt.Errorf("Missing parameter %s", k)
+		}
+	}
+	return t.build(p), nil
+}
+
+func (p AclTemplateParams) acl(r AclResourceType, pattern AclPatternType, name string, ops ...string) []AclRequest {
+	res := make([]AclRequest, len(ops))
+	for i, op := range ops {
+		res[i] = AclRequest{
+			PatternType:    pattern,
+			ResourceType:   r,
+			Name:           name,
+			Principal:      p.Principal,
+			Permission:     op,
+			PermissionType: "ALLOW",
+			Host:           p.Host,
+		}
+	}
+	return res
+}
+
+func (p AclTemplateParams) topic(ops ...string) []AclRequest {
+	return p.acl(TopicResource, p.TopicPattern, p.Topic, ops...)
+}
+
+func (p AclTemplateParams) cluster(ops ...string) []AclRequest {
+	return p.acl(ClusterResource, LiteralPattern, clusterResourceId, ops...)
+}
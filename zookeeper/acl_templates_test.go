@@ -0,0 +1,47 @@
+package zookeeper
+
+import "testing"
+
+func TestAclTemplateExpand(t *testing.T) {
+	params := AclTemplateParams{
+		Principal:       "User:svc",
+		Topic:           "orders",
+		TopicPattern:    PrefixedPattern,
+		Group:           "orders-app",
+		TransactionalId: "orders-tx",
+	}
+	specs := map[string]int{
+		"producer":               3,
+		"consumer":               3,
+		"transactional-producer": 5,
+		"streams-app":            8,
+	}
+	for name, count := range specs {
+		tmpl, ok := AclTemplateByName(name)
+		if !ok {
+			t.Fatalf("Template %s not found", name)
+		}
+		reqs, err := tmpl.Expand(params)
+		if err != nil {
+			t.Fatalf("Template %s: %s", name, err)
+		}
+		if len(reqs) != count {
+			t.Errorf("FAILED! expected %d ACLs for %s, got %d", count, name, len(reqs))
+		}
+		for _, req := range reqs {
+			if req.Principal != params.Principal {
+				t.Errorf("FAILED! %s: wrong principal %s", name, req.Principal)
+			}
+			if req.ResourceType == TopicResource && req.Name == "orders" && req.PatternType != PrefixedPattern {
+				t.Errorf("FAILED! %s: topic ACL should use the requested pattern", name)
+			}
+		}
+	}
+}
+
+func TestAclTemplateRequired(t *testing.T) {
+	tmpl, _ := AclTemplateByName("consumer")
+	if _, err := tmpl.Expand(AclTemplateParams{Principal: "User:svc", Topic: "orders"}); err == nil {
+		t.Error("FAILED! expected error when group is missing")
+	}
+}
@@ -0,0 +1,141 @@
+package zookeeper
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Evaluates ACLs the same way as Kafka's AclAuthorizer, see
+// https://docs.confluent.io/current/kafka/authorization.html#acl-format
+
+const (
+	ReasonDenied      = "denied_by_acl"
+	ReasonAllowed     = "allowed_by_acl"
+	ReasonNoMatch     = "no_matching_allow_acl"
+	ReasonNoAcls      = "no_acls_for_resource"
+	clusterResourceId = "kafka-cluster"
+)
+
+// Operations that are implicitly allowed when another operation is allowed
+var impliedOperations = map[string][]string{
+	"describe":        []string{"read", "write", "delete", "alter"},
+	"describeconfigs": []string{"alterconfigs"},
+}
+
+type AuthorizeRequest struct {
+	Principal    string
+	Host         string
+	Operation    string
+	ResourceType AclResourceType
+	Name         string
+}
+
+type AuthorizeResult struct {
+	Allowed bool
+	Reason  string
+	// Rules that produced the decision, Users only contains the matching entries
+	Rules ACLRules
+}
+
+func (r AuthorizeResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"allowed": r.Allowed,
+		"reason":  r.Reason,
+		"rules":   r.Rules.Details(),
+	})
+}
+
+// Authorize answers if the principal in the request is allowed to perform the
+// operation on the resource given the rules.
+func (me ACLRules) Authorize(req AuthorizeRequest) AuthorizeResult {
+	return authorize(req, me)
+}
+
+func authorize(req AuthorizeRequest, rules ACLRules) AuthorizeResult {
+	if req.ResourceType == ClusterResource && req.Name == "" {
+		req.Name = clusterResourceId
+	}
+	if req.Host == "" {
+		req.Host = "*"
+	}
+	var (
+		op       = normalizeOperation(req.Operation)
+		matching = make(ACLRules, 0)
+		denies   = make(ACLRules, 0)
+		allows   = make(ACLRules, 0)
+	)
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Resource.ResourceType, req.ResourceType.String()) {
+			continue
+		}
+		if resourceMatches(rule.Resource, req.Name) && len(rule.Users) > 0 {
+			matching = append(matching, rule)
+		}
+	}
+	if len(matching) == 0 {
+		return AuthorizeResult{Allowed: false, Reason: ReasonNoAcls, Rules: matching}
+	}
+	for _, rule := range matching {
+		if r, ok := filterUsers(rule, func(u UserACL) bool {
+			return strings.EqualFold(u.PermissionType, "deny") &&
+				userMatches(u, req) && operationMatches(u.Operation, op, false)
+		}); ok {
+			denies = append(denies, r)
+		}
+		if r, ok := filterUsers(rule, func(u UserACL) bool {
+			return strings.EqualFold(u.PermissionType, "allow") &&
+				userMatches(u, req) && operationMatches(u.Operation, op, true)
+		}); ok {
+			allows = append(allows, r)
+		}
+	}
+	if len(denies) > 0 {
+		return AuthorizeResult{Allowed: false, Reason: ReasonDenied, Rules: denies}
+	}
+	if len(allows) > 0 {
+		return AuthorizeResult{Allowed: true, Reason: ReasonAllowed, Rules: allows}
+	}
+	return AuthorizeResult{Allowed: false, Reason: ReasonNoMatch, Rules: allows}
+}
+
+func filterUsers(rule ACLRule, fn func(UserACL) bool) (ACLRule, bool) {
+	users := make([]UserACL, 0)
+	for _, u := range rule.Users {
+		if fn(u) {
+			users = append(users, u)
+		}
+	}
+	rule.Users = users
+	return rule, len(users) > 0
+}
+
+func resourceMatches(r ACLResource, name string) bool {
+	switch strings.ToLower(r.PatternType) {
+	case "literal":
+		return r.Name == "*" || r.Name == name
+	case "prefixed":
+		return strings.HasPrefix(name, r.Name)
+	}
+	return false
+}
+
+func userMatches(u UserACL, req AuthorizeRequest) bool {
+	principal := u.Principal == req.Principal || u.Principal == "User:*"
+	host := u.Host == "*" || u.Host == req.Host
+	return principal && host
+}
+
+func operationMatches(aclOp, op string, allow bool) bool {
+	aclOp = normalizeOperation(aclOp)
+	if aclOp == "all" || aclOp == op {
+		return true
+	}
+	if allow {
+		for _, implied := range impliedOperations[op] {
+			if aclOp == implied {
+				return true
+			}
+		}
+	}
+	return false
+}
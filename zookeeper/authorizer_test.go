@@ -0,0 +1,89 @@
+package zookeeper
+
+import "testing"
+
+func rule(name, pattern string, users ...UserACL) ACLRule {
+	return ACLRule{
+		Resource: ACLResource{Name: name, ResourceType: "TOPIC", PatternType: pattern},
+		Users:    users,
+	}
+}
+
+type authorizeSpec struct {
+	Op       string
+	Name     string
+	Host     string
+	Rules    ACLRules
+	Expected bool
+	Reason   string
+}
+
+// { Principal, PermissionType, Operation, Host }
+func TestAuthorize(t *testing.T) {
+	specs := []authorizeSpec{
+		{"Read", "test", "", ACLRules{}, false, ReasonNoAcls},
+		{"Read", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{rule("other", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "*"})}, false, ReasonNoAcls},
+		{"Read", "test", "", ACLRules{rule("*", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{rule("te", "PREFIXED", UserACL{"User:bob", "ALLOW", "Read", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{rule("test", "PREFIXED", UserACL{"User:bob", "ALLOW", "Read", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{rule("tests", "PREFIXED", UserACL{"User:bob", "ALLOW", "Read", "*"})}, false, ReasonNoAcls},
+		{"Read", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:*", "ALLOW", "ALL", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:alice", "ALLOW", "READ", "*"})}, false, ReasonNoMatch},
+		{"Write", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "*"})}, false, ReasonNoMatch},
+		{"Describe", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "WRITE", "*"})}, true, ReasonAllowed},
+		{"DescribeConfigs", "test", "", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "ALTER_CONFIGS", "*"})}, true, ReasonAllowed},
+		{"Describe", "test", "", ACLRules{
+			rule("*", "LITERAL", UserACL{"User:bob", "ALLOW", "ALL", "*"}),
+			rule("te", "PREFIXED", UserACL{"User:bob", "DENY", "WRITE", "*"})}, true, ReasonAllowed},
+		{"Read", "test", "", ACLRules{
+			rule("*", "LITERAL", UserACL{"User:bob", "ALLOW", "ALL", "*"}),
+			rule("te", "PREFIXED", UserACL{"User:*", "DENY", "READ", "*"})}, false, ReasonDenied},
+		{"Read", "test", "10.0.0.1", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "10.0.0.2"})}, false, ReasonNoMatch},
+		{"Read", "test", "10.0.0.1", ACLRules{rule("test", "LITERAL", UserACL{"User:bob", "ALLOW", "READ", "10.0.0.1"})}, true, ReasonAllowed},
+	}
+	for i, spec := range specs {
+		res := authorize(AuthorizeRequest{
+			Principal:    "User:bob",
+			Host:         spec.Host,
+			Operation:    spec.Op,
+			ResourceType: TopicResource,
+			Name:         spec.Name,
+		}, spec.Rules)
+		if res.Allowed != spec.Expected || res.Reason != spec.Reason {
+			t.Errorf("FAILED spec %d! expected %v (%s) got %v (%s)", i, spec.Expected, spec.Reason, res.Allowed, res.Reason)
+		}
+	}
+}
+
+func TestAuthorizeMatchingRules(t *testing.T) {
+	rules := ACLRules{
+		rule("test", "LITERAL",
+			UserACL{"User:bob", "ALLOW", "READ", "*"},
+			UserACL{"User:alice", "ALLOW", "READ", "*"}),
+		rule("t", "PREFIXED", UserACL{"User:bob", "ALLOW", "WRITE", "*"}),
+	}
+	res := authorize(AuthorizeRequest{Principal: "User:bob", Operation: "Read",
+		ResourceType: TopicResource, Name: "test"}, rules)
+	if len(res.Rules) != 1 {
+		t.Fatalf("Expected 1 matching rule, got %d", len(res.Rules))
+	}
+	if len(res.Rules[0].Users) != 1 || res.Rules[0].Users[0].Principal != "User:bob" {
+		t.Errorf("Expected only the matching user in rule, got %v", res.Rules[0].Users)
+	}
+}
+
+func TestAclOperationFromString(t *testing.T) {
+	for in, expected := range map[string]string{
+		"alter_configs":    "AlterConfigs",
+		"IDEMPOTENT_WRITE": "IdempotentWrite",
+		"Read":             "Read",
+	} {
+		if op, err := AclOperationFromString(in); err != nil || op != expected {
+			t.Errorf("FAILED! expected %s for %s, got %s", expected, in, op)
+		}
+	}
+	if _, err := AclOperationFromString("fly"); err == nil {
+		t.Error("FAILED! expected error for unknown operation")
+	}
+}
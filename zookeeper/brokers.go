@@ -13,6 +13,7 @@ type B struct {
 	Host      string   `json:"host"`
 	Port      int      `json:"port"`
 	Id        int      `json:"id"`
+	Rack      string   `json:"rack"`
 }
 
 // Controller struct from Zookeeper Path "/controller"
@@ -71,22 +71,21 @@ func (p Permission) IdempotentWrite(resource string) bool {
 
 func PermissionsFor(username string) (Permissions, error) {
 	// Using AdminPermissions here since we need permissions to see all rules
-	cAcls, err := ClusterAcls(AdminPermissions)
-	if err != nil {
-		return Permissions{}, err
-	}
-	tAcls, err := TopicAcls(AdminPermissions)
-	if err != nil {
-		return Permissions{}, err
-	}
-	gAcls, err := GroupAcls(AdminPermissions)
+	rules, err := Acls(AdminPermissions)
 	if err != nil {
 		return Permissions{}, err
 	}
+	return PermissionsFromRules(username, rules), nil
+}
+
+// PermissionsFromRules builds the permission model for a user from all ACL rules
+func PermissionsFromRules(username string, rules ACLRules) Permissions {
 	return Permissions{
-		Cluster: permissionsMap(username, cAcls),
-		Topic:   permissionsMap(username, tAcls),
-		Group:   permissionsMap(username, gAcls)}, nil
+		Cluster:         permissionsMap(username, rules.OfType(ClusterResource)),
+		Topic:           permissionsMap(username, rules.OfType(TopicResource)),
+		Group:           permissionsMap(username, rules.OfType(GroupResource)),
+		TransactionalId: permissionsMap(username, rules.OfType(TransactionalIdResource)),
+		DelegationToken: permissionsMap(username, rules.OfType(DelegationTokenResource))}
 }
 
 func permissionsMap(username string, rules []ACLRule) []Permission {
@@ -1,9 +1,11 @@
 package zookeeper
 
 type Permissions struct {
-	Cluster []Permission
-	Topic   []Permission
-	Group   []Permission
+	Cluster         []Permission
+	Topic           []Permission
+	Group           []Permission
+	TransactionalId []Permission
+	DelegationToken []Permission
 }
 
 func (p Permissions) DescribeAcls() bool {
@@ -81,6 +83,34 @@ func (p Permissions) DescribeTopic(resource string) bool {
 func (p Permissions) DescribeGroup(resource string) bool {
 	return p.describe(p.Group, resource)
 }
+func (p Permissions) ReadTransactionalId(resource string) bool {
+	return p.describe(p.TransactionalId, resource) || p.describe(p.Cluster, "kafka-cluster")
+}
+func (p Permissions) ReadDelegationToken(resource string) bool {
+	return p.describe(p.DelegationToken, resource) || p.describe(p.Cluster, "kafka-cluster")
+}
+
+// ReadAcl checks if the rules for a resource should be visible, mirrors the
+// filters used when listing ACLs
+func (p Permissions) ReadAcl(r ACLResource) bool {
+	t, err := AclResourceFromString(r.ResourceType)
+	if err != nil {
+		return false
+	}
+	switch t {
+	case TopicResource:
+		return p.ReadTopic(r.Name)
+	case GroupResource:
+		return p.ReadGroup(r.Name)
+	case ClusterResource:
+		return p.ReadCluster(r.Name)
+	case TransactionalIdResource:
+		return p.ReadTransactionalId(r.Name)
+	case DelegationTokenResource:
+		return p.ReadDelegationToken(r.Name)
+	}
+	return false
+}
 
 func (p Permissions) AlterConfigsCluster() bool {
 	return p.check(p.Cluster, func(p Permission) bool {
@@ -127,7 +157,9 @@ func (p Permissions) ListGroups() bool {
 
 var AllowAll = []Permission{Permission{"All", "Allow", "LITERAL", "*"}}
 var AdminPermissions = Permissions{
-	Cluster: AllowAll,
-	Topic:   AllowAll,
-	Group:   AllowAll,
+	Cluster:         AllowAll,
+	Topic:           AllowAll,
+	Group:           AllowAll,
+	TransactionalId: AllowAll,
+	DelegationToken: AllowAll,
 }
@@ -74,3 +74,17 @@ func TestListAcls(t *testing.T) {
 		}
 	}
 }
+
+func TestReadTransactionalId(t *testing.T) {
+	specs := []spec{
+		{Permissions{TransactionalId: []Permission{{"Describe", "Allow", "PREFIXED", "tx-"}}}, true},
+		{Permissions{TransactionalId: []Permission{{"Describe", "Allow", "LITERAL", "other"}}}, false},
+		{Permissions{Cluster: []Permission{{"Describe", "Allow", "LITERAL", "kafka-cluster"}}}, true},
+		{AdminPermissions, true},
+	}
+	for _, spec := range specs {
+		if spec.In.ReadTransactionalId("tx-1") != spec.Expected {
+			t.Errorf("FAILED! expected %v for Permission %v", spec.Expected, spec.In)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package zookeeper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// Data owned by the manager is kept under this path, Kafka never reads it
+const (
+	managerPath   = "/cloudkarafka-manager"
+	topicTagsPath = managerPath + "/topic-tags"
+	// Touched on every change of topic tags, managers watch it to reload
+	topicTagsChangedPath = managerPath + "/topic-tags-changed"
+)
+
+var Classifications = []string{"public", "internal", "confidential", "restricted"}
+
+// TopicTags is metadata about a topic that Kafka doesn't know about, like
+// who owns it and how sensitive the data is
+type TopicTags struct {
+	Owner          string            `json:"owner,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Classification string            `json:"classification,omitempty"`
+	OnCall         string            `json:"on_call,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	UpdatedBy      string            `json:"updated_by,omitempty"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+func (me TopicTags) Validate() error {
+	if me.Classification != "" {
+		valid := false
+		for _, c := range Classifications {
+			valid = valid || c == me.Classification
+		}
+		if !valid {
+			return fmt.Errorf("classification must be one of %s", strings.Join(Classifications, ", "))
+		}
+	}
+	for k := range me.Labels {
+		if k == "" || strings.ContainsAny(k, "=,") {
+			return fmt.Errorf("label %q can't be empty or contain '=' or ','", k)
+		}
+	}
+	return nil
+}
+
+// AllTopicTags returns the tags of all topics that have any
+func AllTopicTags() (map[string]TopicTags, error) {
+	res := make(map[string]TopicTags)
+	names, err := all(topicTagsPath, func(string) bool { return true })
+	if err == PathDoesNotExistsErr {
+		return res, nil
+	} else if err != nil {
+		return res, err
+	}
+	for _, name := range names {
+		var tags TopicTags
+		if err = get(topicTagsPath+"/"+name, &tags); err != nil {
+			return res, err
+		}
+		res[name] = tags
+	}
+	return res, nil
+}
+
+func SetTopicTags(name string, tags TopicTags) error {
+	path := topicTagsPath + "/" + name
+	var err error
+	if Exists(path) {
+		err = set(path, tags)
+	} else if err = createManagerPaths(); err == nil {
+		err = createPersistent(path, tags)
+	}
+	if err != nil {
+		return err
+	}
+	return touchTopicTags()
+}
+
+func DeleteTopicTags(name string) error {
+	path := topicTagsPath + "/" + name
+	err := conn.Delete(path, -1)
+	if err == zk.ErrNoNode {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return touchTopicTags()
+}
+
+func createManagerPaths() error {
+	for _, p := range []string{managerPath, topicTagsPath} {
+		if err := createPersistent(p, ""); err != nil && err != zk.ErrNodeExists {
+			return err
+		}
+	}
+	return nil
+}
+
+func touchTopicTags() error {
+	now := time.Now().UnixNano()
+	if Exists(topicTagsChangedPath) {
+		return set(topicTagsChangedPath, now)
+	}
+	if err := createManagerPaths(); err != nil {
+		return err
+	}
+	err := createPersistent(topicTagsChangedPath, now)
+	if err == zk.ErrNodeExists {
+		return set(topicTagsChangedPath, now)
+	}
+	return err
+}
+
+// WatchTopicTags calls fn every time topic tags are changed, by this or
+// any other manager, until the connection is closed
+func WatchTopicTags(fn func()) {
+	for {
+		if !Exists(topicTagsChangedPath) {
+			if err := touchTopicTags(); err != nil {
+				time.Sleep(10 * time.Second)
+				continue
+			}
+		}
+		_, _, events, err := conn.GetW(topicTagsChangedPath)
+		if err != nil {
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		e, ok := <-events
+		if !ok || e.Err == zk.ErrClosing {
+			return
+		}
+		fn()
+	}
+}
@@ -2,6 +2,7 @@ package zookeeper
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 // Correct permission for each operation is here: https://docs.confluent.io/current/kafka/authorization.html#acl-format
@@ -36,6 +37,41 @@ func (me ACLRules) Size() int {
 	return len(me)
 }
 
+// Find returns the first rule for the resource name
+func (me ACLRules) Find(name string) (ACLRule, bool) {
+	for _, r := range me {
+		if r.Resource.Name == name {
+			return r, true
+		}
+	}
+	return ACLRule{}, false
+}
+
+// OfType returns the rules for one resource type
+func (me ACLRules) OfType(t AclResourceType) ACLRules {
+	res := make(ACLRules, 0)
+	for _, r := range me {
+		if strings.EqualFold(r.Resource.ResourceType, t.String()) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Principal returns every rule the principal appears in, with Users limited
+// to the entries for that principal.
+func (me ACLRules) Principal(principal string) ACLRules {
+	res := make(ACLRules, 0)
+	for _, rule := range me {
+		if r, ok := filterUsers(rule, func(u UserACL) bool {
+			return u.Principal == principal
+		}); ok {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
 func (r ACLRule) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
 		"name":          r.Resource.Name,
@@ -44,3 +80,24 @@ func (r ACLRule) MarshalJSON() ([]byte, error) {
 		"users":         len(r.Users),
 	})
 }
+
+// ACLRuleDetails marshals the rule including each user entry instead of only
+// the number of users.
+type ACLRuleDetails ACLRule
+
+func (r ACLRuleDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"name":          r.Resource.Name,
+		"resource_type": r.Resource.ResourceType,
+		"pattern_type":  r.Resource.PatternType,
+		"users":         r.Users,
+	})
+}
+
+func (me ACLRules) Details() []ACLRuleDetails {
+	res := make([]ACLRuleDetails, len(me))
+	for i, r := range me {
+		res[i] = ACLRuleDetails(r)
+	}
+	return res
+}
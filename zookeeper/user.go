@@ -15,17 +15,17 @@ var (
 	userAlreadyExists = errors.New("User already exists")
 )
 
-type users []string
+type UserList []string
 
-func (u users) Get(i int) interface{} {
+func (u UserList) Get(i int) interface{} {
 	return u[i]
 }
 
-func (u users) Size() int {
+func (u UserList) Size() int {
 	return len(u)
 }
 
-func Users(username string, p Permissions) (users, error) {
+func Users(username string, p Permissions) (UserList, error) {
 	users, err := all("/config/users", func(usr string) bool {
 		return p.ReadCluster("kafka-cluster") || usr == username
 	})
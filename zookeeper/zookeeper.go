@@ -90,15 +90,7 @@ func createPersistent(path string, data interface{}) error {
 }
 
 func create(path string, data interface{}, flag int) error {
-	var (
-		bytes []byte
-		err   error
-	)
-	if str, ok := data.(string); ok {
-		bytes = []byte(str)
-	} else {
-		bytes, err = json.Marshal(data)
-	}
+	bytes, err := encode(data)
 	if err != nil {
 		return err
 	}
@@ -106,6 +98,14 @@ func create(path string, data interface{}, flag int) error {
 	return err
 }
 
+// encode stores strings as they are and everything else as JSON
+func encode(data interface{}) ([]byte, error) {
+	if str, ok := data.(string); ok {
+		return []byte(str), nil
+	}
+	return json.Marshal(data)
+}
+
 func set(path string, data interface{}) error {
 	_, stat, err := conn.Exists(path)
 	if err != nil {